@@ -14,7 +14,7 @@ export const WebSocketProvider = ({ children }: WebSocketProviderProps) => {
 
   useEffect(() => {
     if (user && user.gameId) {
-      initWebSocket(user.gameId, user.username);
+      initWebSocket(user.gameId);
     }
   }, [initWebSocket, user]);
 
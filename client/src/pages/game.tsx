@@ -374,7 +374,8 @@ export default function GameScreen() {
 
       const { data } = await api.get("/api/game/" + id);
 
-      if (data.game.state === "lobby") {
+      // Back to the lobby between rounds or while the next one counts down
+      if (data.game.state !== "in-progress") {
         navigate("/lobby/" + id);
         return;
       }
@@ -8,13 +8,40 @@ type WebSocketMessage = {
     | "player_left"
     | "game_started"
     | "new_guess"
-    | "game_over";
+    | "game_over"
+    | "game_master_guess"
+    | "player_ready"
+    | "ack"
+    | "error"
+    | "snapshot"
+    | "presence_changed"
+    | "state_changed"
+    | "scoreboard"
+    | "match_over"
+    | "deadline"
+    | "round_tick"
+    | "players_eliminated"
+    | "team_guess"
+    | "teams_changed";
+  id?: string;
+  seq?: number;
   payload: unknown;
 };
 
+// Commands the server accepts over the socket, replied to with an ack or error
+type WebSocketCommand =
+  | "guess"
+  | "start"
+  | "leave"
+  | "ready"
+  | "ping"
+  | "resync";
+
 // Constants
 const RECONNECT_DELAY = 3000;
 const MAX_RECONNECT_ATTEMPTS = 3;
+// Unauthorized, not in the game, game not found
+const REJECTED_CLOSE_CODES = [4001, 4003, 4004];
 
 // Atoms
 export const wsConnectionAtom = atom<WebSocket | null>(null);
@@ -23,7 +50,7 @@ export const wsConnectedAtom = atom<boolean>(false);
 // Derived atom for creating/managing the connection
 export const wsManagerAtom = atom(
   (get) => get(wsConnectionAtom),
-  (get, set, gameId: string, username: string) => {
+  (get, set, gameId: string) => {
     // Close existing connection if any
     const existingWs = get(wsConnectionAtom);
     if (existingWs && existingWs.readyState === WebSocket.OPEN) {
@@ -33,15 +60,23 @@ export const wsManagerAtom = atom(
 
     let reconnectAttempts = 0;
     let reconnectTimeout: number;
+    // Last event sequence number seen, sent on reconnect to replay missed events
+    let lastSeq: number | null = null;
 
     const connectWebSocket = () => {
+      // The JWT is sent as a subprotocol since browsers can't set headers on a WebSocket
+      const token = localStorage.getItem("token") || "";
+
       // Create new WebSocket connection with proper URL encoding
       const ws = new WebSocket(
         //For loclahost
-        // `ws://localhost:8080/ws/${encodeURIComponent(gameId)}?username=${encodeURIComponent(username)}`,
+        // `ws://localhost:8080/ws/${encodeURIComponent(gameId)}`,
 
         //For Production
-        `wss://multiplayer-wordle-production.up.railway.app/ws/${encodeURIComponent(gameId)}?username=${encodeURIComponent(username)}`,
+        `wss://multiplayer-wordle-production.up.railway.app/ws/${encodeURIComponent(gameId)}${
+          lastSeq !== null ? `?since=${lastSeq}` : ""
+        }`,
+        ["bearer", token],
       );
 
       // Set a connection timeout
@@ -66,8 +101,12 @@ export const wsManagerAtom = atom(
           `WebSocket disconnected: code=${event.code}, reason=${event.reason}`,
         );
 
-        // Attempt reconnection if not deliberately closed
-        if (event.code !== 1000 && reconnectAttempts < MAX_RECONNECT_ATTEMPTS) {
+        // Attempt reconnection if not deliberately closed or rejected by the server
+        if (
+          event.code !== 1000 &&
+          !REJECTED_CLOSE_CODES.includes(event.code) &&
+          reconnectAttempts < MAX_RECONNECT_ATTEMPTS
+        ) {
           reconnectAttempts++;
           console.log(`Reconnecting... Attempt ${reconnectAttempts}`);
           reconnectTimeout = window.setTimeout(
@@ -83,7 +122,10 @@ export const wsManagerAtom = atom(
 
       ws.onmessage = (event) => {
         try {
-          const data = JSON.parse(event.data);
+          const data: WebSocketMessage = JSON.parse(event.data);
+          if (typeof data.seq === "number") {
+            lastSeq = data.seq;
+          }
           console.log("WebSocket message received:", data);
         } catch (error) {
           console.error("Error parsing WebSocket message:", error);
@@ -137,3 +179,33 @@ export const useWebSocketMessage = <T>(
     return () => ws.removeEventListener("message", messageHandler);
   }, [ws, messageHandler]);
 };
+
+// Sends a command and resolves with the ack payload or rejects with the error payload
+let commandCounter = 0;
+export const sendWebSocketCommand = <T = unknown>(
+  ws: WebSocket,
+  type: WebSocketCommand,
+  payload?: unknown,
+): Promise<T> => {
+  const id = `${Date.now()}-${++commandCounter}`;
+
+  return new Promise<T>((resolve, reject) => {
+    const onMessage = (event: MessageEvent) => {
+      try {
+        const data: WebSocketMessage = JSON.parse(event.data);
+        if (data.id !== id) return;
+        ws.removeEventListener("message", onMessage);
+        if (data.type === "ack") {
+          resolve(data.payload as T);
+        } else {
+          reject(data.payload);
+        }
+      } catch (error) {
+        console.error("Error handling WebSocket reply:", error);
+      }
+    };
+
+    ws.addEventListener("message", onMessage);
+    ws.send(JSON.stringify({ id, type, payload }));
+  });
+};
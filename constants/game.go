@@ -0,0 +1,4 @@
+package constants
+
+// Number of guesses each player gets per round
+const MaxAttempts = 6
@@ -0,0 +1,281 @@
+package constants
+
+// Word lengths a game can be played with
+const (
+	MinWordLength     = 4
+	MaxWordLength     = 8
+	DefaultWordLength = 5
+)
+
+// Answer pools keyed by word length
+var WordLists = map[int][]string{
+	4: FourLetterWords,
+	5: WordList,
+	6: SixLetterWords,
+	7: SevenLetterWords,
+	8: EightLetterWords,
+}
+
+var FourLetterWords = []string{
+	"able", "acid", "aged", "also", "area", "army", "away", "baby", "back", "ball",
+	"band", "bank", "base", "bath", "bear", "beat", "been", "beer", "bell", "belt",
+	"best", "bill", "bird", "blow", "blue", "boat", "body", "bomb", "bond", "bone",
+	"book", "boom", "born", "boss", "both", "bowl", "bulk", "burn", "bush", "busy",
+	"cake", "call", "calm", "came", "camp", "card", "care", "case", "cash", "cast",
+	"cell", "chat", "chef", "chip", "city", "clay", "club", "coal", "coat", "code",
+	"cold", "come", "cook", "cool", "cope", "copy", "core", "corn", "cost", "crew",
+	"crop", "dark", "data", "date", "dawn", "days", "dead", "deal", "dear", "debt",
+	"deck", "deep", "deer", "desk", "dial", "diet", "dirt", "disc", "dish", "dock",
+	"does", "done", "door", "dose", "down", "draw", "drew", "drop", "drug", "drum",
+	"dual", "duck", "dust", "duty", "each", "earn", "ease", "east", "easy", "edge",
+	"else", "even", "ever", "evil", "exit", "face", "fact", "fade", "fail", "fair",
+	"fall", "fame", "farm", "fast", "fate", "fear", "feed", "feel", "feet", "fell",
+	"felt", "file", "fill", "film", "find", "fine", "fire", "firm", "fish", "five",
+	"flag", "flat", "fled", "flew", "flow", "folk", "food", "foot", "ford", "form",
+	"fort", "four", "free", "frog", "from", "fuel", "full", "fund", "gain", "game",
+	"gate", "gave", "gear", "gift", "girl", "give", "glad", "glow", "goal", "goat",
+	"goes", "gold", "golf", "gone", "good", "gray", "grew", "grey", "grid", "grin",
+	"grip", "grow", "gulf", "hair", "half", "hall", "hand", "hang", "hard", "harm",
+	"hate", "have", "head", "hear", "heat", "held", "hell", "help", "here", "hero",
+	"hide", "high", "hill", "hint", "hire", "hold", "hole", "holy", "home", "hope",
+	"horn", "host", "hour", "huge", "hung", "hunt", "hurt", "idea", "inch", "into",
+	"iron", "item", "jazz", "join", "joke", "jump", "jury", "just", "keen", "keep",
+	"kept", "kick", "kind", "king", "kiss", "knee", "knew", "knot", "know", "lack",
+	"lady", "laid", "lake", "lamp", "land", "lane", "last", "late", "lawn", "lead",
+	"leaf", "lean", "left", "lend", "lens", "less", "life", "lift", "like", "limb",
+	"line", "link", "lion", "list", "live", "load", "loan", "lock", "loft", "logo",
+	"long", "look", "lord", "lose", "loss", "lost", "loud", "love", "luck", "lung",
+	"made", "mail", "main", "make", "male", "many", "mark", "mask", "mass", "meal",
+	"mean", "meat", "meet", "melt", "menu", "mere", "mild", "milk", "mind", "mine",
+	"miss", "mode", "mood", "moon", "more", "most", "move", "much", "must", "myth",
+	"nail", "name", "navy", "near", "neat", "neck", "need", "nest", "news", "next",
+	"nice", "nine", "node", "none", "noon", "norm", "nose", "note", "okay", "once",
+	"only", "open", "oral", "oven", "over", "pace", "pack", "page", "paid", "pain",
+	"pair", "palm", "park", "part", "pass", "past", "path", "peak", "pick", "pile",
+	"pine", "pink", "pipe", "plan", "play", "plot", "plus", "poem", "poet", "pole",
+	"poll", "pond", "pool", "poor", "port", "pose", "post", "pour", "pray", "pull",
+	"pump", "pure", "push", "quit", "race", "rack", "rage", "rail", "rain", "rank",
+	"rare", "rate", "read", "real", "rear", "rely", "rent", "rest", "rice", "rich",
+	"ride", "ring", "rise", "risk", "road", "rock", "role", "roll", "roof", "room",
+	"root", "rope", "rose", "rule", "rush", "safe", "said", "sail", "sake", "sale",
+	"salt", "same", "sand", "save", "seal", "seat", "seed", "seek", "seem", "seen",
+	"self", "sell", "send", "sent", "ship", "shoe", "shop", "shot", "show", "shut",
+	"sick", "side", "sign", "silk", "sing", "sink", "site", "size", "skin", "slip",
+	"slow", "snow", "soft", "soil", "sold", "sole", "some", "song", "soon", "sort",
+	"soul", "spin", "spot", "star", "stay", "step", "stop", "such", "suit", "sure",
+	"swim", "tail", "take", "tale", "talk", "tall", "tank", "tape", "task", "team",
+	"tear", "tell", "tend", "tent", "term", "test", "text", "than", "that", "them",
+	"then", "they", "thin", "this", "thus", "tide", "tile", "till", "time", "tiny",
+	"tone", "took", "tool", "tour", "town", "tree", "trip", "true", "tune", "turn",
+	"twin", "type", "unit", "upon", "used", "user", "vast", "very", "view", "vote",
+	"wage", "wait", "wake", "walk", "wall", "want", "warm", "warn", "wash", "wave",
+	"weak", "wear", "week", "well", "went", "were", "west", "what", "when", "whom",
+	"wide", "wife", "wild", "will", "wind", "wine", "wing", "wire", "wise", "wish",
+	"with", "wolf", "wood", "word", "wore", "work", "worn", "wrap", "yard", "yeah",
+	"year", "yell", "your", "zero", "zone",
+}
+
+var SixLetterWords = []string{
+	"abroad", "absent", "accept", "access", "across", "acting", "action", "active",
+	"actual", "advice", "advise", "affect", "afford", "afraid", "agency", "agenda",
+	"almost", "always", "amount", "animal", "annual", "answer", "anyone", "anyway",
+	"appeal", "appear", "around", "arrive", "artist", "aspect", "assess", "assist",
+	"assume", "attack", "attend", "author", "autumn", "avenue", "backed", "barely",
+	"basket", "battle", "beauty", "became", "become", "before", "behalf", "behave",
+	"behind", "belief", "belong", "beside", "better", "beyond", "bishop", "bitter",
+	"blonde", "bloody", "border", "borrow", "bother", "bottle", "bottom", "bought",
+	"branch", "breath", "bridge", "bright", "broken", "budget", "burden", "bureau",
+	"butter", "button", "camera", "cancer", "cannot", "carbon", "career", "castle",
+	"casual", "caught", "center", "centre", "chance", "change", "charge", "chosen",
+	"church", "circle", "client", "closed", "closer", "coffee", "column", "combat",
+	"coming", "common", "comply", "copper", "corner", "costly", "county", "couple",
+	"course", "covers", "create", "credit", "crisis", "custom", "damage", "danger",
+	"dealer", "debate", "decade", "decide", "defeat", "defend", "define", "degree",
+	"demand", "depend", "deputy", "desert", "design", "desire", "detail", "detect",
+	"device", "differ", "dinner", "direct", "doctor", "dollar", "domain", "double",
+	"driven", "driver", "during", "easily", "eating", "editor", "effect", "effort",
+	"eighth", "either", "eleven", "emerge", "empire", "employ", "enable", "ending",
+	"energy", "engage", "engine", "enough", "ensure", "entire", "entity", "equity",
+	"escape", "estate", "ethnic", "exceed", "except", "excess", "expand", "expect",
+	"expert", "export", "extend", "extent", "fabric", "facing", "factor", "failed",
+	"fairly", "fallen", "family", "famous", "father", "fellow", "female", "figure",
+	"filing", "finger", "finish", "fiscal", "flight", "flying", "follow", "forced",
+	"forest", "forget", "formal", "format", "former", "foster", "fought", "fourth",
+	"friend", "frozen", "future", "garden", "gather", "gender", "gentle", "gifted",
+	"ginger", "global", "golden", "ground", "growth", "guilty", "handed", "handle",
+	"happen", "hardly", "headed", "health", "height", "hidden", "holder", "honest",
+	"hunger", "hunter", "impact", "import", "income", "indeed", "injury", "inside",
+	"intend", "intent", "invest", "island", "itself", "jacket", "joined", "junior",
+	"killed", "kitten", "labour", "ladder", "lately", "latter", "launch", "lawyer",
+	"leader", "league", "leaves", "legacy", "length", "lesson", "letter", "lights",
+	"likely", "linked", "liquid", "listen", "little", "living", "lonely", "losing",
+	"lovely", "luxury", "mainly", "making", "manage", "manner", "marble", "margin",
+	"marine", "market", "master", "matter", "medium", "member", "memory", "mental",
+	"merely", "method", "middle", "minute", "mirror", "mobile", "modern", "modest",
+	"moment", "mostly", "mother", "motion", "moving", "murder", "museum", "mutual",
+	"myself", "narrow", "nation", "native", "nature", "nearby", "nearly", "needle",
+	"nobody", "normal", "notice", "notion", "number", "object", "obtain", "office",
+	"offset", "online", "option", "orange", "origin", "output", "packed", "palace",
+	"parent", "partly", "patent", "people", "period", "permit", "person", "phrase",
+	"picked", "planet", "player", "please", "plenty", "pocket", "poetry", "police",
+	"policy", "potato", "powder", "prefer", "pretty", "prince", "prison", "profit",
+	"proper", "proven", "public", "purple", "pursue", "puzzle", "rabbit", "racing",
+	"random", "rarely", "rather", "rating", "reader", "really", "reason", "recall",
+	"recent", "record", "reduce", "reform", "regard", "regime", "region", "relate",
+	"relief", "remain", "remote", "remove", "repair", "repeat", "replay", "report",
+	"rescue", "resort", "result", "retail", "retain", "return", "reveal", "review",
+	"reward", "riding", "rising", "robust", "rocket", "ruling", "safety", "salary",
+	"sample", "saving", "scheme", "school", "screen", "script", "search", "season",
+	"second", "secret", "sector", "secure", "seeing", "select", "seller", "senior",
+	"series", "server", "settle", "severe", "shadow", "should", "signal", "silent",
+	"silver", "simple", "simply", "single", "sister", "sketch", "slight", "smooth",
+	"soccer", "social", "solely", "source", "speech", "spirit", "spoken", "spread",
+	"spring", "square", "stable", "status", "steady", "stolen", "strain", "strand",
+	"stream", "street", "stress", "strict", "strike", "string", "stroke", "strong",
+	"struck", "studio", "stupid", "submit", "sudden", "suffer", "summer", "summit",
+	"supply", "surely", "survey", "switch", "symbol", "system", "taking", "talent",
+	"target", "taught", "tenant", "tender", "tennis", "thanks", "theory", "thirty",
+	"though", "thread", "threat", "thrown", "ticket", "timber", "timing", "tissue",
+	"toward", "travel", "treaty", "trying", "twelve", "twenty", "unable", "unique",
+	"united", "unless", "unlike", "update", "useful", "valley", "varied", "vendor",
+	"versus", "victim", "vision", "visual", "volume", "walker", "wealth", "weekly",
+	"weight", "window", "winner", "winter", "within", "wonder", "wooden", "worker",
+	"writer", "yellow",
+}
+
+var SevenLetterWords = []string{
+	"ability", "absence", "academy", "account", "accused", "achieve", "acquire", "address",
+	"advance", "adverse", "advised", "adviser", "against", "airline", "airport", "alcohol",
+	"already", "amazing", "ancient", "another", "anxiety", "anxious", "anybody", "applied",
+	"arrange", "arrival", "article", "assault", "attempt", "attract", "auction", "average",
+	"backing", "balance", "banking", "barrier", "battery", "bearing", "beating", "because",
+	"bedroom", "believe", "beneath", "benefit", "besides", "between", "billion", "binding",
+	"brother", "brought", "builder", "burning", "cabinet", "caliber", "calling", "capable",
+	"capital", "captain", "caption", "capture", "careful", "carrier", "caution", "ceiling",
+	"central", "century", "certain", "chamber", "channel", "chapter", "charity", "charter",
+	"checked", "chicken", "chronic", "circuit", "classic", "climate", "closing", "clothes",
+	"collect", "college", "combine", "comfort", "command", "comment", "compact", "company",
+	"compare", "compete", "complex", "concept", "concern", "concert", "conduct", "confirm",
+	"connect", "consent", "consist", "contact", "contain", "content", "contest", "context",
+	"control", "convert", "correct", "council", "counsel", "counter", "country", "crucial",
+	"crystal", "culture", "current", "cutting", "dealing", "decided", "decline", "default",
+	"defence", "deficit", "deliver", "density", "deposit", "desktop", "despite", "destroy",
+	"develop", "devoted", "diamond", "digital", "discuss", "disease", "display", "dispute",
+	"distant", "diverse", "divided", "drawing", "driving", "dynamic", "eastern", "economy",
+	"edition", "elderly", "element", "engaged", "enhance", "essence", "evening", "evident",
+	"exactly", "examine", "example", "excited", "exclude", "exhibit", "expense", "explain",
+	"explore", "express", "extreme", "factory", "faculty", "failing", "failure", "fashion",
+	"feature", "federal", "feeling", "fiction", "fifteen", "filling", "finance", "finding",
+	"fishing", "fitness", "foreign", "forever", "formula", "fortune", "forward", "founder",
+	"freedom", "further", "gallery", "gateway", "general", "genetic", "genuine", "greater",
+	"hanging", "heading", "healthy", "hearing", "heavily", "helpful", "helping", "herself",
+	"highway", "himself", "history", "holding", "holiday", "housing", "however", "hundred",
+	"husband", "illegal", "illness", "imagine", "imaging", "improve", "include", "initial",
+	"inquiry", "insight", "install", "instant", "instead", "intense", "interim", "involve",
+	"jointly", "journal", "journey", "justice", "justify", "keeping", "killing", "kingdom",
+	"kitchen", "knowing", "landing", "largely", "lasting", "leading", "learned", "leisure",
+	"liberal", "liberty", "library", "license", "limited", "listing", "logical", "loyalty",
+	"machine", "manager", "married", "massive", "maximum", "meaning", "measure", "medical",
+	"meeting", "mention", "message", "million", "mineral", "minimal", "minimum", "missing",
+	"mission", "mistake", "mixture", "monitor", "monthly", "morning", "musical", "mystery",
+	"natural", "neither", "nervous", "network", "neutral", "notable", "nothing", "nowhere",
+	"nuclear", "nursing", "obvious", "offense", "officer", "ongoing", "opening", "operate",
+	"opinion", "optical", "organic", "outcome", "outdoor", "outlook", "outside", "overall",
+	"package", "painted", "parking", "partial", "partner", "passage", "passing", "passion",
+	"passive", "patient", "pattern", "payable", "payment", "penalty", "pending", "pension",
+	"percent", "perfect", "perform", "perhaps", "picture", "pioneer", "plastic", "pointed",
+	"popular", "portion", "poverty", "precise", "predict", "premier", "premium", "prepare",
+	"present", "prevent", "primary", "printer", "privacy", "private", "problem", "proceed",
+	"process", "produce", "product", "profile", "program", "project", "promise", "promote",
+	"protect", "protein", "protest", "provide", "publish", "purpose", "pushing", "qualify",
+	"quality", "quarter", "radical", "railway", "readily", "reading", "reality", "realize",
+	"receipt", "receive", "recover", "reflect", "regular", "related", "release", "remains",
+	"removal", "removed", "replace", "request", "require", "reserve", "resolve", "respect",
+	"respond", "restore", "retired", "revenue", "reverse", "rolling", "romance", "roughly",
+	"routine", "running", "satisfy", "science", "section", "segment", "serious", "service",
+	"serving", "session", "setting", "seventh", "several", "shortly", "showing", "silence",
+	"silicon", "similar", "sitting", "sixteen", "skilled", "smoking", "society", "somehow",
+	"someone", "speaker", "special", "species", "sponsor", "station", "storage", "strange",
+	"stretch", "student", "studied", "subject", "succeed", "success", "suggest", "summary",
+	"support", "suppose", "supreme", "surface", "surgery", "surplus", "survive", "suspect",
+	"sustain", "teacher", "telling", "tension", "theatre", "therapy", "thereby", "thought",
+	"through", "tonight", "totally", "touched", "towards", "traffic", "trouble", "turning",
+	"typical", "uniform", "unknown", "unusual", "upgrade", "upscale", "utility", "variety",
+	"various", "vehicle", "venture", "version", "veteran", "victory", "viewing", "village",
+	"violent", "virtual", "visible", "waiting", "walking", "wanting", "warning", "warrant",
+	"wealthy", "weather", "website", "wedding", "weekend", "welcome", "welfare", "western",
+	"whereas", "whether", "willing", "winning", "without", "witness", "working", "writing",
+	"written",
+}
+
+var EightLetterWords = []string{
+	"absolute", "abstract", "academic", "accepted", "accident", "accuracy", "accurate",
+	"achieved", "acquired", "activity", "actually", "addition", "adequate", "adjacent",
+	"adjusted", "advanced", "advisory", "advocate", "affected", "aircraft", "alliance",
+	"although", "aluminum", "analysis", "announce", "anything", "anywhere", "apparent",
+	"appendix", "approach", "approval", "argument", "artistic", "assembly", "assuming",
+	"athletic", "attached", "attitude", "attorney", "audience", "autonomy", "aviation",
+	"bachelor", "bacteria", "baseball", "bathroom", "becoming", "birthday", "boundary",
+	"breaking", "breeding", "building", "bulletin", "business", "calendar", "campaign",
+	"capacity", "casualty", "catching", "category", "cautious", "cellular", "ceremony",
+	"chairman", "champion", "chemical", "children", "circular", "civilian", "clinical",
+	"clothing", "collapse", "colonial", "colorful", "commence", "commerce", "complain",
+	"complete", "composed", "compound", "comprise", "computer", "conclude", "concrete",
+	"conflict", "confused", "congress", "consider", "constant", "consumer", "continue",
+	"contract", "contrary", "contrast", "convince", "corridor", "coverage", "covering",
+	"creation", "creative", "criminal", "critical", "crossing", "cultural", "currency",
+	"customer", "database", "daughter", "daylight", "deadline", "deciding", "decision",
+	"decrease", "deferred", "definite", "delicate", "delivery", "describe", "designer",
+	"detailed", "diabetes", "dialogue", "diameter", "directly", "director", "disabled",
+	"disaster", "disclose", "discount", "discover", "disorder", "disposal", "distance",
+	"distinct", "district", "dividend", "division", "doctrine", "document", "domestic",
+	"dominant", "download", "dramatic", "duration", "dynamics", "earnings", "economic",
+	"educated", "efficacy", "eighteen", "election", "electric", "eligible", "emerging",
+	"emphasis", "employee", "endeavor", "engaging", "engineer", "enormous", "entirely",
+	"entrance", "envelope", "equality", "equation", "estimate", "evaluate", "eventual",
+	"everyday", "everyone", "evidence", "exchange", "exciting", "exercise", "explicit",
+	"exposure", "extended", "external", "facility", "familiar", "featured", "feedback",
+	"festival", "finished", "firewall", "flexible", "floating", "football", "foothill",
+	"forecast", "formerly", "fourteen", "fraction", "frequent", "friendly", "frontier",
+	"function", "generate", "generous", "geometry", "graduate", "graphics", "grateful",
+	"guardian", "guidance", "handling", "hardware", "heritage", "highland", "historic",
+	"homeless", "hospital", "humanity", "identify", "identity", "ideology", "imperial",
+	"incident", "included", "increase", "indicate", "indirect", "industry", "informal",
+	"informed", "inherent", "initiate", "innocent", "inspired", "instance", "integral",
+	"intended", "interact", "interest", "interior", "internal", "interval", "intimate",
+	"invasion", "involved", "isolated", "judgment", "judicial", "junction", "keyboard",
+	"landlord", "language", "laughing", "learning", "leverage", "lifetime", "lighting",
+	"likewise", "limiting", "literary", "location", "magazine", "magnetic", "maintain",
+	"majority", "marginal", "marriage", "material", "maturity", "maximize", "meantime",
+	"measured", "medicine", "medieval", "memorial", "merchant", "midnight", "military",
+	"minimize", "minister", "ministry", "minority", "mobility", "modeling", "moderate",
+	"momentum", "monetary", "moreover", "mortgage", "mountain", "mounting", "movement",
+	"multiple", "national", "negative", "neighbor", "nineteen", "northern", "notebook",
+	"numerous", "observed", "occasion", "offering", "official", "offshore", "operator",
+	"opponent", "opposite", "optimism", "optional", "ordinary", "organize", "oriented",
+	"original", "overcome", "overseas", "painting", "parallel", "parental", "patience",
+	"peaceful", "pentagon", "perceive", "personal", "persuade", "petition", "physical",
+	"pipeline", "platform", "pleasant", "pleasure", "politics", "portable", "portrait",
+	"position", "positive", "possible", "powerful", "practice", "preserve", "pressing",
+	"pressure", "previous", "princess", "printing", "priority", "probable", "probably",
+	"producer", "profound", "progress", "property", "proposal", "prospect", "protocol",
+	"provided", "provider", "province", "publicly", "purchase", "pursuant", "quantity",
+	"question", "rational", "reaction", "received", "receiver", "recovery", "regional",
+	"register", "relation", "relative", "relevant", "reliable", "reliance", "religion",
+	"remember", "renowned", "repeated", "reporter", "republic", "required", "research",
+	"reserved", "resident", "resigned", "resource", "response", "restrict", "revision",
+	"rigorous", "romantic", "sampling", "scenario", "schedule", "scrutiny", "seasonal",
+	"secondly", "security", "sensible", "sentence", "separate", "sequence", "sergeant",
+	"shipping", "shortage", "shoulder", "simplify", "situated", "slightly", "software",
+	"solution", "somebody", "somewhat", "southern", "speaking", "specific", "spectrum",
+	"sporting", "standard", "standing", "stranger", "strategy", "strength", "striking",
+	"strongly", "struggle", "stunning", "suburban", "suitable", "sunshine", "superior",
+	"supposed", "surgical", "surprise", "survival", "sweeping", "swimming", "symbolic",
+	"sympathy", "syndrome", "tactical", "tailored", "takeover", "tangible", "taxation",
+	"taxpayer", "teaching", "teenager", "tendency", "terminal", "terrible", "thinking",
+	"thirteen", "thousand", "together", "tomorrow", "training", "transfer", "traveled",
+	"treasury", "triangle", "tropical", "turnover", "ultimate", "umbrella", "universe",
+	"unlawful", "unlikely", "valuable", "variable", "vertical", "violence", "volatile",
+	"warranty", "weakness", "weighted", "whatever", "whenever", "wherever", "wildlife",
+	"wireless", "withdraw", "woodland", "workshop", "yourself",
+}
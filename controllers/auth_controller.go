@@ -5,6 +5,7 @@ import (
 	"log"
 	"multiplayer-wordle/initialisers"
 	"multiplayer-wordle/models"
+	"multiplayer-wordle/stats"
 	"os"
 	"time"
 
@@ -187,9 +188,17 @@ func Me(c *fiber.Ctx) error {
 
 	user.Password = ""
 
+	playerStats, err := stats.Get(db, user.ID)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to fetch stats",
+		})
+	}
+
 	return c.Status(fiber.StatusOK).JSON(fiber.Map{
 		"message": "Success",
 		"user":    user,
+		"stats":   playerStats,
 	})
 
 }
@@ -0,0 +1,251 @@
+package controllers
+
+import (
+	"errors"
+	"fmt"
+	"multiplayer-wordle/constants"
+	"multiplayer-wordle/daily"
+	"multiplayer-wordle/dictionary"
+	"multiplayer-wordle/game"
+	"multiplayer-wordle/initialisers"
+	"multiplayer-wordle/models"
+	"multiplayer-wordle/wordselect"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/jackc/pgx/v5/pgconn"
+	"gorm.io/gorm"
+)
+
+var errDailyFinished = errors.New("daily challenge already finished")
+
+// dailyWordError answers a request the daily word couldn't be picked for
+func dailyWordError(c *fiber.Ctx, err error) error {
+	if errors.Is(err, wordselect.ErrNoSecret) {
+		return c.Status(fiber.StatusServiceUnavailable).JSON(fiber.Map{
+			"error": "The daily challenge is not available",
+		})
+	}
+	return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+		"error": "Failed to fetch the daily word",
+	})
+}
+
+func GetDaily(c *fiber.Ctx) error {
+	username, ok := c.Locals("username").(string)
+	if !ok {
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+			"error": "Unauthorized access",
+		})
+	}
+
+	db := initialisers.DB
+
+	var user models.Player
+	if err := db.Where("username = ?", username).First(&user).Error; err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to fetch user",
+		})
+	}
+
+	date := daily.Today()
+	word, err := daily.WordFor(db, date)
+	if err != nil {
+		return dailyWordError(c, err)
+	}
+
+	var guesses []models.DailyGuess
+	if err := db.Where("player_id = ? AND date = ?", user.ID, date).Order("attempt_number").Find(&guesses).Error; err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to fetch guesses",
+		})
+	}
+
+	var result models.DailyResult
+	if err := db.Where("player_id = ? AND date = ?", user.ID, date).Limit(1).Find(&result).Error; err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to fetch result",
+		})
+	}
+
+	response := fiber.Map{
+		"message":     "Success",
+		"date":        date,
+		"wordLength":  constants.DefaultWordLength,
+		"maxAttempts": constants.MaxAttempts,
+		"guesses":     guesses,
+		"finished":    result.ID != 0,
+	}
+
+	// The word is only revealed once the player is done for the day
+	if result.ID != 0 {
+		response["result"] = result
+		response["word"] = word
+	}
+
+	return c.Status(fiber.StatusOK).JSON(response)
+}
+
+func DailyGuess(c *fiber.Ctx) error {
+	username, ok := c.Locals("username").(string)
+	if !ok {
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+			"error": "Unauthorized access",
+		})
+	}
+
+	db := initialisers.DB
+
+	var user models.Player
+	if err := db.Where("username = ?", username).First(&user).Error; err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to fetch user",
+		})
+	}
+
+	var body struct {
+		GuessWord string `json:"guessWord"`
+	}
+
+	if err := c.BodyParser(&body); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Failed to parse request body",
+		})
+	}
+
+	guessWord, err := dictionary.Normalize(body.GuessWord)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "The guess word must only contain letters",
+			"code":  "invalid_characters",
+		})
+	}
+
+	if len(guessWord) != constants.DefaultWordLength {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": fmt.Sprintf("The guess word must be exactly %d letters", constants.DefaultWordLength),
+		})
+	}
+
+	if !dictionary.IsWord(guessWord) {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error":     "Not a valid word",
+			"code":      "not_a_word",
+			"guessWord": guessWord,
+		})
+	}
+
+	date := daily.Today()
+	word, err := daily.WordFor(db, date)
+	if err != nil {
+		return dailyWordError(c, err)
+	}
+	isCorrect, feedback := game.Score(guessWord, word)
+
+	var guess models.DailyGuess
+	var result *models.DailyResult
+
+	err = db.Transaction(func(tx *gorm.DB) error {
+		var finished int64
+		if err := tx.Model(&models.DailyResult{}).Where("player_id = ? AND date = ?", user.ID, date).Count(&finished).Error; err != nil {
+			return err
+		}
+		if finished > 0 {
+			return errDailyFinished
+		}
+
+		var attempts int64
+		if err := tx.Model(&models.DailyGuess{}).Where("player_id = ? AND date = ?", user.ID, date).Count(&attempts).Error; err != nil {
+			return err
+		}
+
+		// Attempt numbers are assigned here, the unique index rejects a
+		// concurrent request that counted the same attempts
+		guess = models.DailyGuess{
+			PlayerID:      user.ID,
+			Date:          date,
+			GuessWord:     guessWord,
+			Feedback:      feedback,
+			AttemptNumber: uint(attempts),
+		}
+		if err := tx.Create(&guess).Error; err != nil {
+			return err
+		}
+
+		if isCorrect || attempts+1 >= constants.MaxAttempts {
+			result = &models.DailyResult{
+				PlayerID:     user.ID,
+				Date:         date,
+				Username:     user.Username,
+				Solved:       isCorrect,
+				AttemptsUsed: uint(attempts + 1),
+				CompletedAt:  time.Now(),
+			}
+			if err := tx.Create(result).Error; err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+
+	if errors.Is(err, errDailyFinished) {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "You have already finished today's challenge",
+		})
+	}
+	// 23505 is unique_violation, a concurrent guess took the same attempt
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) && pgErr.Code == "23505" {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Another guess was submitted at the same time, please try again",
+		})
+	}
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to create a guess",
+		})
+	}
+
+	response := fiber.Map{
+		"message":  "Guess word submitted successfully",
+		"guess":    guess,
+		"finished": result != nil,
+	}
+	if result != nil {
+		response["result"] = result
+		response["word"] = word
+	}
+
+	return c.Status(fiber.StatusOK).JSON(response)
+}
+
+func GetDailyLeaderboard(c *fiber.Ctx) error {
+	db := initialisers.DB
+
+	date := c.Query("date", daily.Today())
+	if _, err := time.Parse("2006-01-02", date); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Date must be in YYYY-MM-DD format",
+		})
+	}
+
+	limit, offset := getPagination(c)
+
+	var results []models.DailyResult
+	if err := db.Where("date = ?", date).
+		Order("solved DESC, attempts_used, completed_at, username").
+		Limit(limit).
+		Offset(offset).
+		Find(&results).Error; err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to fetch leaderboard",
+		})
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"message": "Success",
+		"date":    date,
+		"results": results,
+	})
+}
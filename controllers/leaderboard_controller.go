@@ -0,0 +1,52 @@
+package controllers
+
+import (
+	"errors"
+	"multiplayer-wordle/initialisers"
+	"multiplayer-wordle/leaderboard"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+func GetLeaderboard(c *fiber.Ctx) error {
+	db := initialisers.DB
+
+	limit, offset := getPagination(c)
+
+	gameID := c.QueryInt("gameId", 0)
+	if gameID < 0 {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid game ID",
+		})
+	}
+
+	opts := leaderboard.Options{
+		Metric: leaderboard.Metric(c.Query("metric", string(leaderboard.MetricWins))),
+		Period: leaderboard.Period(c.Query("period", string(leaderboard.PeriodAll))),
+		GameID: uint(gameID),
+		Limit:  limit,
+		Offset: offset,
+	}
+
+	entries, total, err := leaderboard.Query(db, opts)
+	if err != nil {
+		if errors.Is(err, leaderboard.ErrInvalidMetric) || errors.Is(err, leaderboard.ErrInvalidPeriod) {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"error": err.Error(),
+			})
+		}
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to fetch leaderboard",
+		})
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"message": "Success",
+		"metric":  opts.Metric,
+		"period":  opts.Period,
+		"total":   total,
+		"limit":   limit,
+		"offset":  offset,
+		"entries": entries,
+	})
+}
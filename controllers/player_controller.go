@@ -0,0 +1,82 @@
+package controllers
+
+import (
+	"errors"
+	"multiplayer-wordle/initialisers"
+	"multiplayer-wordle/models"
+	"multiplayer-wordle/rating"
+	"multiplayer-wordle/stats"
+
+	"github.com/gofiber/fiber/v2"
+	"gorm.io/gorm"
+)
+
+// Helper function to respond when the player in the route can't be fetched
+func playerLookupError(c *fiber.Ctx, err error) error {
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+			"error": "Player not found",
+		})
+	}
+	return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+		"error": "Failed to fetch player",
+	})
+}
+
+func GetPlayerStats(c *fiber.Ctx) error {
+	db := initialisers.DB
+
+	var player models.Player
+	if err := db.Where("username = ?", c.Params("username")).First(&player).Error; err != nil {
+		return playerLookupError(c, err)
+	}
+
+	playerStats, err := stats.Get(db, player.ID)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to fetch stats",
+		})
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"message":  "Success",
+		"username": player.Username,
+		"stats":    playerStats,
+	})
+}
+
+func GetPlayerRating(c *fiber.Ctx) error {
+	db := initialisers.DB
+
+	var player models.Player
+	if err := db.Where("username = ?", c.Params("username")).First(&player).Error; err != nil {
+		return playerLookupError(c, err)
+	}
+
+	playerRating, err := rating.Get(db, player.ID)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to fetch rating",
+		})
+	}
+
+	limit, offset := getPagination(c)
+
+	var history []models.RatingHistory
+	if err := db.Where("player_id = ?", player.ID).
+		Order("created_at DESC, id DESC").
+		Limit(limit).
+		Offset(offset).
+		Find(&history).Error; err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to fetch rating history",
+		})
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"message":  "Success",
+		"username": player.Username,
+		"rating":   playerRating,
+		"history":  history,
+	})
+}
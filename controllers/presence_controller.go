@@ -0,0 +1,132 @@
+package controllers
+
+import (
+	"log"
+	"multiplayer-wordle/initialisers"
+	"multiplayer-wordle/models"
+	"multiplayer-wordle/websockets"
+	"strconv"
+	"time"
+)
+
+// DisconnectGracePeriod is how long a player may be disconnected before they
+// are removed from the game. Zero or less keeps them in the game forever
+var DisconnectGracePeriod = 2 * time.Minute
+
+const (
+	// How often players disconnected for longer than the grace period are looked for
+	disconnectSweepInterval = 15 * time.Second
+	// A player who dropped off still counts toward the end of the round for
+	// this long, so reloading the page doesn't end it under them
+	reconnectWindow = 10 * time.Second
+)
+
+// RegisterPresenceHandlers reacts to players connecting to and dropping off
+// a game's WebSocket
+func RegisterPresenceHandlers() {
+	websockets.OnPresenceChange(recordDisconnect)
+	websockets.OnPresenceChange(endRoundIfExhausted)
+	go sweepDisconnectedPlayers()
+}
+
+// playerOnline reports whether a player has the game open, or dropped off
+// recently enough that they are likely reconnecting
+func playerOnline(gameID uint, username string) bool {
+	if websockets.Hub.IsOnline(gameID, username) {
+		return true
+	}
+
+	var count int64
+	err := initialisers.DB.Table("game_players").
+		Joins("JOIN players ON players.id = game_players.player_id").
+		Where("game_players.game_id = ? AND players.username = ? AND game_players.disconnected_at > ?", gameID, username, time.Now().Add(-reconnectWindow)).
+		Count(&count).Error
+	if err != nil {
+		log.Printf("Failed to check whether %s is reconnecting to game %d: %v", username, gameID, err)
+		return false
+	}
+	return count > 0
+}
+
+// When a player drops off and doesn't come back, the ones still online may
+// all be out of attempts already and nobody is left to send the guess that
+// ends the round
+func endRoundIfExhausted(gameID uint, username string, online bool) {
+	if online {
+		return
+	}
+
+	time.AfterFunc(reconnectWindow, func() {
+		if err := gameEngine().EndRoundIfExhausted(gameID); err != nil {
+			log.Printf("Failed to end game %d after %s disconnected: %v", gameID, username, err)
+		}
+	})
+}
+
+// recordDisconnect keeps when a player dropped off in the database, so their
+// removal survives a restart and whichever instance sweeps first carries it out
+func recordDisconnect(gameID uint, username string, online bool) {
+	db := initialisers.DB
+
+	var disconnectedAt *time.Time
+	if !online {
+		now := time.Now()
+		disconnectedAt = &now
+	}
+
+	player := db.Model(&models.Player{}).Select("id").Where("username = ?", username)
+	err := db.Model(&models.GamePlayer{}).
+		Where("game_id = ? AND player_id = (?)", gameID, player).
+		Update("disconnected_at", disconnectedAt).Error
+	if err != nil {
+		log.Printf("Failed to record that %s went offline in game %d: %v", username, gameID, err)
+	}
+}
+
+// sweepDisconnectedPlayers removes the players who stayed disconnected past
+// the grace period, once at startup for those who dropped off while the
+// server was down and then periodically
+func sweepDisconnectedPlayers() {
+	if DisconnectGracePeriod <= 0 {
+		return
+	}
+
+	ticker := time.NewTicker(disconnectSweepInterval)
+	defer ticker.Stop()
+	for {
+		removeDisconnectedPlayers()
+		<-ticker.C
+	}
+}
+
+// A player who stays disconnected past the grace period leaves the game the
+// same way as through LeaveGame, so their GameID is freed for a new game
+func removeDisconnectedPlayers() {
+	var expired []struct {
+		GameID   uint
+		Username string
+	}
+	err := initialisers.DB.Table("game_players").
+		Select("game_players.game_id, players.username").
+		Joins("JOIN players ON players.id = game_players.player_id AND players.game_id = game_players.game_id").
+		Where("game_players.disconnected_at < ?", time.Now().Add(-DisconnectGracePeriod)).
+		Scan(&expired).Error
+	if err != nil {
+		log.Println("Failed to look for disconnected players:", err)
+		return
+	}
+
+	for _, player := range expired {
+		// A reconnect that raced with the sweep keeps them in the game
+		if websockets.Hub.IsOnline(player.GameID, player.Username) {
+			continue
+		}
+
+		if _, err := leaveGame(player.Username, strconv.FormatUint(uint64(player.GameID), 10)); err != nil {
+			// Most likely they already left or the game is gone
+			log.Printf("Could not remove disconnected player %s from game %d: %v", player.Username, player.GameID, err)
+			continue
+		}
+		log.Printf("Removed %s from game %d after being disconnected for %s", player.Username, player.GameID, DisconnectGracePeriod)
+	}
+}
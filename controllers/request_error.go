@@ -0,0 +1,67 @@
+package controllers
+
+import (
+	"errors"
+	"multiplayer-wordle/game"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// RequestError is a failed game action, shared by the REST and WebSocket
+// handlers so both report the same status and body
+type RequestError struct {
+	Status int
+	Body   fiber.Map
+}
+
+func (e *RequestError) Error() string {
+	message, _ := e.Body["error"].(string)
+	return message
+}
+
+// Payload is the body sent back over the WebSocket
+func (e *RequestError) Payload() interface{} {
+	return e.Body
+}
+
+func requestError(status int, body fiber.Map) *RequestError {
+	return &RequestError{Status: status, Body: body}
+}
+
+// Helper function to send the result of a game action as a REST response
+func respond(c *fiber.Ctx, response fiber.Map, err error) error {
+	if err != nil {
+		if reqErr, ok := err.(*RequestError); ok {
+			return c.Status(reqErr.Status).JSON(reqErr.Body)
+		}
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Something went wrong",
+		})
+	}
+	return c.Status(fiber.StatusOK).JSON(response)
+}
+
+// Helper function to turn a game engine error into the matching response
+func engineError(err error) error {
+	var gameErr *game.Error
+	if !errors.As(err, &gameErr) {
+		return err
+	}
+
+	status := fiber.StatusBadRequest
+	switch gameErr.Kind {
+	case game.KindNotFound:
+		status = fiber.StatusNotFound
+	case game.KindInternal:
+		status = fiber.StatusInternalServerError
+	}
+
+	body := fiber.Map{"error": gameErr.Message}
+	if gameErr.Code != "" {
+		body["code"] = gameErr.Code
+	}
+	for key, value := range gameErr.Fields {
+		body[key] = value
+	}
+	return requestError(status, body)
+}
@@ -0,0 +1,109 @@
+package controllers
+
+import (
+	"multiplayer-wordle/initialisers"
+	"multiplayer-wordle/models"
+	"strconv"
+
+	"github.com/gofiber/fiber/v2"
+	"gorm.io/gorm"
+)
+
+const (
+	defaultPageSize = 20
+	maxPageSize     = 100
+)
+
+// Reads limit and offset query params, defaulting to the first page
+func getPagination(c *fiber.Ctx) (int, int) {
+	limit := c.QueryInt("limit", defaultPageSize)
+	if limit <= 0 {
+		limit = defaultPageSize
+	}
+	if limit > maxPageSize {
+		limit = maxPageSize
+	}
+
+	offset := c.QueryInt("offset", 0)
+	if offset < 0 {
+		offset = 0
+	}
+
+	return limit, offset
+}
+
+// Preloads the players and guesses of each round in play order
+func preloadRoundDetails(db *gorm.DB) *gorm.DB {
+	return db.
+		Preload("Players").
+		Preload("Guesses", func(db *gorm.DB) *gorm.DB {
+			return db.Order("player_id, attempt_number")
+		})
+}
+
+func GetGameRounds(c *fiber.Ctx) error {
+	db := initialisers.DB
+
+	gameID, err := strconv.ParseUint(c.Params("gameID"), 10, 64)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid game ID",
+		})
+	}
+
+	limit, offset := getPagination(c)
+
+	var rounds []models.Round
+	if err := preloadRoundDetails(db).
+		Where("game_id = ?", gameID).
+		Order("ended_at DESC").
+		Limit(limit).
+		Offset(offset).
+		Find(&rounds).Error; err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to fetch rounds",
+		})
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"message": "Success",
+		"rounds":  rounds,
+	})
+}
+
+func GetMyHistory(c *fiber.Ctx) error {
+	username, ok := c.Locals("username").(string)
+	if !ok {
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+			"error": "Unauthorized access",
+		})
+	}
+
+	db := initialisers.DB
+
+	var user models.Player
+	if err := db.Where("username = ?", username).First(&user).Error; err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to fetch user",
+		})
+	}
+
+	limit, offset := getPagination(c)
+
+	var rounds []models.Round
+	if err := preloadRoundDetails(db).
+		Where("id IN (?)", db.Model(&models.RoundPlayer{}).Select("round_id").Where("player_id = ?", user.ID)).
+		Order("ended_at DESC").
+		Limit(limit).
+		Offset(offset).
+		Find(&rounds).Error; err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to fetch history",
+		})
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"message": "Success",
+		"rounds":  rounds,
+	})
+}
@@ -0,0 +1,143 @@
+package controllers
+
+import (
+	"fmt"
+	"multiplayer-wordle/dictionary"
+	"multiplayer-wordle/initialisers"
+	"multiplayer-wordle/models"
+	"strconv"
+
+	"github.com/gofiber/fiber/v2"
+	"gorm.io/gorm"
+)
+
+const maxScheduledWords = 50
+
+// Helper function to make sure only the lobby admin can see or change the schedule
+func fetchScheduleAdmin(c *fiber.Ctx, db *gorm.DB) (models.Game, bool, error) {
+	username, ok := c.Locals("username").(string)
+	if !ok {
+		return models.Game{}, false, c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+			"error": "Unauthorized access",
+		})
+	}
+
+	var user models.Player
+	if err := db.Where("username = ?", username).First(&user).Error; err != nil {
+		return models.Game{}, false, c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to fetch user",
+		})
+	}
+
+	gameID, err := strconv.ParseUint(c.Params("gameID"), 10, 64)
+	if err != nil {
+		return models.Game{}, false, c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid game ID",
+		})
+	}
+
+	if user.GameID != uint(gameID) {
+		return models.Game{}, false, c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "You are not in this game",
+		})
+	}
+
+	if !user.IsAdmin {
+		return models.Game{}, false, c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "You are not the admin",
+		})
+	}
+
+	var game models.Game
+	if err := db.Where("id = ?", gameID).First(&game).Error; err != nil {
+		return models.Game{}, false, c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to fetch game",
+		})
+	}
+
+	return game, true, nil
+}
+
+func GetWordSchedule(c *fiber.Ctx) error {
+	db := initialisers.DB
+
+	game, ok, err := fetchScheduleAdmin(c, db)
+	if !ok {
+		return err
+	}
+
+	var schedule []models.ScheduledWord
+	if err := db.Where("game_id = ? AND used_at IS NULL", game.ID).Order("position, id").Find(&schedule).Error; err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to fetch schedule",
+		})
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"message":  "Success",
+		"schedule": schedule,
+	})
+}
+
+// Replaces the words still waiting to be played with a new list
+func UpdateWordSchedule(c *fiber.Ctx) error {
+	db := initialisers.DB
+
+	game, ok, err := fetchScheduleAdmin(c, db)
+	if !ok {
+		return err
+	}
+
+	var body struct {
+		Words []string `json:"words"`
+	}
+
+	if err := c.BodyParser(&body); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Failed to parse request body",
+		})
+	}
+
+	if len(body.Words) > maxScheduledWords {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": fmt.Sprintf("You can schedule at most %d words", maxScheduledWords),
+		})
+	}
+
+	schedule := make([]models.ScheduledWord, 0, len(body.Words))
+	for i, word := range body.Words {
+		normalized, err := dictionary.Validate(word)
+		if err != nil || len(normalized) != game.WordLength {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"error": fmt.Sprintf("%q is not a valid %d letter word", word, game.WordLength),
+				"code":  "not_a_word",
+			})
+		}
+
+		schedule = append(schedule, models.ScheduledWord{
+			GameID:   game.ID,
+			Position: i,
+			Word:     normalized,
+		})
+	}
+
+	err = db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("game_id = ? AND used_at IS NULL", game.ID).Delete(&models.ScheduledWord{}).Error; err != nil {
+			return err
+		}
+		if len(schedule) == 0 {
+			return nil
+		}
+		return tx.Create(&schedule).Error
+	})
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to update schedule",
+		})
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"message":  "Schedule updated successfully",
+		"schedule": schedule,
+	})
+}
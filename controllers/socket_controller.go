@@ -0,0 +1,51 @@
+package controllers
+
+import (
+	"encoding/json"
+	"multiplayer-wordle/websockets"
+	"strconv"
+)
+
+// RegisterSocketCommands exposes the game actions over the WebSocket. They
+// run the same code as the REST handlers, with the username taken from the
+// authenticated connection
+func RegisterSocketCommands() {
+	websockets.RegisterCommand("guess", socketGuess)
+	websockets.RegisterCommand("start", socketStart)
+	websockets.RegisterCommand("leave", socketLeave)
+}
+
+func socketGuess(conn websockets.Connection, payload json.RawMessage) (interface{}, error) {
+	body := GuessInput{}
+	if err := decodePayload(payload, &body); err != nil {
+		return nil, err
+	}
+	return submitGuess(conn.Username, socketGameID(conn), body)
+}
+
+func socketStart(conn websockets.Connection, payload json.RawMessage) (interface{}, error) {
+	body := StartGameInput{}
+	if err := decodePayload(payload, &body); err != nil {
+		return nil, err
+	}
+	return startGame(conn.Username, socketGameID(conn), body)
+}
+
+func socketLeave(conn websockets.Connection, payload json.RawMessage) (interface{}, error) {
+	return leaveGame(conn.Username, socketGameID(conn))
+}
+
+func socketGameID(conn websockets.Connection) string {
+	return strconv.FormatUint(uint64(conn.GameID), 10)
+}
+
+// An empty payload is allowed, handlers validate the fields themselves
+func decodePayload(payload json.RawMessage, out interface{}) error {
+	if len(payload) == 0 || string(payload) == "null" {
+		return nil
+	}
+	if err := json.Unmarshal(payload, out); err != nil {
+		return websockets.ErrInvalidPayload
+	}
+	return nil
+}
@@ -0,0 +1,56 @@
+package daily
+
+import (
+	"log"
+	"multiplayer-wordle/constants"
+	"multiplayer-wordle/wordselect"
+	"os"
+	"sync"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+const dateLayout = "2006-01-02"
+
+var warnOnce sync.Once
+
+// Selector picks the daily word, it can be swapped out for a curated list or in tests
+var Selector wordselect.Selector = wordselect.Seeded{Secret: secret}
+
+// Date returns the UTC calendar date a daily challenge belongs to
+func Date(t time.Time) string {
+	return t.UTC().Format(dateLayout)
+}
+
+// Today returns the date of the current daily challenge
+func Today() string {
+	return Date(time.Now())
+}
+
+// secret keeps the daily word unpredictable, without it anyone could work out
+// tomorrow's word from the public word list. It is empty when neither
+// variable is set, and the daily is not served then
+func secret() []byte {
+	seed := os.Getenv("DAILY_WORD_SECRET")
+	if seed == "" {
+		seed = os.Getenv("JWT_SECRET")
+		warnOnce.Do(func() {
+			if seed == "" {
+				log.Println("Neither DAILY_WORD_SECRET nor JWT_SECRET is set, the daily challenge is disabled")
+			} else {
+				log.Println("DAILY_WORD_SECRET is not set, falling back to JWT_SECRET for the daily word")
+			}
+		})
+	}
+	return []byte(seed)
+}
+
+// WordFor picks the daily word for a date, every player and every server
+// replica gets the same word for the same date
+func WordFor(db *gorm.DB, date string) (string, error) {
+	return Selector.Select(db, wordselect.Request{
+		WordLength: constants.DefaultWordLength,
+		Date:       date,
+	})
+}
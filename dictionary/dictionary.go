@@ -0,0 +1,116 @@
+package dictionary
+
+import (
+	"bufio"
+	"embed"
+	"errors"
+	"io"
+	"log"
+	"multiplayer-wordle/constants"
+	"os"
+	"strings"
+	"sync"
+)
+
+// Accepted guesses for every word length, kept separate from the answer pools
+// in constants.WordLists
+//
+//go:embed data/*.txt
+var acceptedGuesses embed.FS
+
+var (
+	ErrInvalidCharacters = errors.New("word must only contain letters from a to z")
+	ErrNotAWord          = errors.New("not a valid word")
+)
+
+var (
+	words    map[string]struct{}
+	loadOnce sync.Once
+)
+
+// Load builds the set of accepted words from the embedded lists, the answer
+// pools and, if DICTIONARY_PATH is set, an extra newline separated word file
+func Load() {
+	loadOnce.Do(func() {
+		words = make(map[string]struct{})
+
+		files, err := acceptedGuesses.ReadDir("data")
+		if err != nil {
+			log.Println("Failed to read embedded dictionary:", err)
+		}
+		for _, entry := range files {
+			file, err := acceptedGuesses.Open("data/" + entry.Name())
+			if err != nil {
+				log.Println("Failed to open embedded dictionary:", err)
+				continue
+			}
+			addWords(file)
+			file.Close()
+		}
+
+		// Every possible answer must always be a valid guess
+		for _, list := range constants.WordLists {
+			for _, word := range list {
+				words[word] = struct{}{}
+			}
+		}
+
+		path := os.Getenv("DICTIONARY_PATH")
+		if path == "" {
+			return
+		}
+
+		file, err := os.Open(path)
+		if err != nil {
+			log.Println("Failed to open dictionary file:", err)
+			return
+		}
+		defer file.Close()
+
+		addWords(file)
+	})
+}
+
+func addWords(r io.Reader) {
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		word, err := Normalize(scanner.Text())
+		if err != nil || word == "" {
+			continue
+		}
+		words[word] = struct{}{}
+	}
+	if err := scanner.Err(); err != nil {
+		log.Println("Error reading dictionary:", err)
+	}
+}
+
+// Normalize trims and lowercases a word and rejects anything that is not a-z
+func Normalize(word string) (string, error) {
+	word = strings.ToLower(strings.TrimSpace(word))
+	for _, r := range word {
+		if r < 'a' || r > 'z' {
+			return "", ErrInvalidCharacters
+		}
+	}
+	return word, nil
+}
+
+// IsWord reports whether an already normalized word is an accepted guess
+func IsWord(word string) bool {
+	Load()
+	_, ok := words[word]
+	return ok
+}
+
+// Validate normalizes a word and checks it against the accepted guesses
+func Validate(word string) (string, error) {
+	normalized, err := Normalize(word)
+	if err != nil {
+		return "", err
+	}
+	if !IsWord(normalized) {
+		return normalized, ErrNotAWord
+	}
+	return normalized, nil
+}
@@ -0,0 +1,42 @@
+package game
+
+import (
+	"fmt"
+	"multiplayer-wordle/constants"
+	"multiplayer-wordle/models"
+)
+
+// MaxSharedAttempts is the largest pool of attempts a co-op team can be given
+const MaxSharedAttempts = 10
+
+// In co-op the whole game plays one board. Any player may make the next
+// guess, its attempt number is the row of the shared board it fills, and the
+// game's row lock makes sure only one guess can take each row
+
+// IsCoop reports whether the players share one board
+func IsCoop(game models.Game) bool {
+	return game.Mode == models.GameModeCoop
+}
+
+// sharedAttempts is the size of the team's pool of attempts
+func sharedAttempts(game models.Game) int {
+	if game.SharedAttempts < 1 {
+		return constants.MaxAttempts
+	}
+	return game.SharedAttempts
+}
+
+// checkSharedAttempt makes sure a co-op guess fills the next free row of the
+// board, so two players racing for the same row can't both get it
+func checkSharedAttempt(game models.Game, attemptNumber uint) error {
+	next := uint(len(game.Guesses))
+	switch {
+	case int(next) >= sharedAttempts(game):
+		return invalid("Your team has no attempts left")
+	case attemptNumber < next:
+		return invalidCode("Your team already used this attempt", "attempt_taken")
+	case attemptNumber > next:
+		return invalidCode(fmt.Sprintf("Attempt %d is next", next+1), "wrong_attempt")
+	}
+	return nil
+}
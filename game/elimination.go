@@ -0,0 +1,117 @@
+package game
+
+import (
+	"multiplayer-wordle/models"
+	"sort"
+	"strings"
+)
+
+// A battle royale plays round after round, knocking out the worst players of
+// each one, until a single player is left. Knocked out players stay in the
+// game and watch
+
+// IsElimination reports whether the game is a battle royale
+func IsElimination(game models.Game) bool {
+	return game.Mode == models.GameModeElimination
+}
+
+// IsEliminated reports whether the player is out of the battle royale
+func IsEliminated(game models.Game, playerID uint) bool {
+	for _, id := range game.Eliminated {
+		if id == playerID {
+			return true
+		}
+	}
+	return false
+}
+
+// Contenders returns the players still guessing, everyone but the game master
+// and the eliminated
+func Contenders(game models.Game) []models.Player {
+	var contenders []models.Player
+	for _, player := range game.Players {
+		if IsGameMaster(game, player.ID) || IsEliminated(game, player.ID) {
+			continue
+		}
+		contenders = append(contenders, player)
+	}
+	return contenders
+}
+
+// everyoneFinishes reports whether a solve leaves the round running for the
+// others, instead of winning it outright
+func everyoneFinishes(game models.Game) bool {
+	return IsRace(game) || IsElimination(game)
+}
+
+// eliminate picks who the round knocks out. Anyone who didn't solve the word
+// is out if someone did, the slowest solvers are out if everyone did, and
+// the players whose best guess was furthest off are out if nobody did. A
+// round that would knock out everyone knocks out nobody
+func eliminate(round models.Round) []uint {
+	var solved, unsolved []models.RoundPlayer
+	for _, player := range round.Players {
+		if player.Solved {
+			solved = append(solved, player)
+		} else {
+			unsolved = append(unsolved, player)
+		}
+	}
+
+	var out []uint
+	switch {
+	case len(solved) > 0 && len(unsolved) > 0:
+		for _, player := range unsolved {
+			out = append(out, player.PlayerID)
+		}
+
+	case len(solved) > 0:
+		sort.SliceStable(solved, func(i, j int) bool {
+			return slower(solved[i], solved[j])
+		})
+		for _, player := range solved {
+			if slower(solved[0], player) {
+				break
+			}
+			out = append(out, player.PlayerID)
+		}
+
+	default:
+		closest := make(map[uint]int)
+		for _, guess := range round.Guesses {
+			if points := closeness(guess.Feedback); points > closest[guess.PlayerID] {
+				closest[guess.PlayerID] = points
+			}
+		}
+
+		worst := -1
+		for _, player := range unsolved {
+			if worst == -1 || closest[player.PlayerID] < worst {
+				worst = closest[player.PlayerID]
+			}
+		}
+		for _, player := range unsolved {
+			if closest[player.PlayerID] == worst {
+				out = append(out, player.PlayerID)
+			}
+		}
+	}
+
+	if len(out) == len(round.Players) {
+		return nil
+	}
+	return out
+}
+
+// slower reports whether a solved the word after b, or as fast in more attempts
+func slower(a, b models.RoundPlayer) bool {
+	if a.SolvedAt != nil && b.SolvedAt != nil && !a.SolvedAt.Equal(*b.SolvedAt) {
+		return a.SolvedAt.After(*b.SolvedAt)
+	}
+	return a.AttemptsUsed > b.AttemptsUsed
+}
+
+// closeness scores how near a guess came: two per green letter, one per yellow
+func closeness(feedback string) int {
+	return 2*strings.Count(feedback, "2") + strings.Count(feedback, "1")
+}
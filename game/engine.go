@@ -0,0 +1,729 @@
+package game
+
+import (
+	"errors"
+	"log"
+	"multiplayer-wordle/models"
+	"time"
+)
+
+// Events is told about every change to a game so it can notify the players,
+// the WebSocket hub in the server
+type Events interface {
+	PlayerJoined(game models.Game)
+	PlayerLeft(game models.Game, player models.Player)
+	GameStarted(game models.Game)
+	NewGuess(game models.Game, guess models.Guess)
+	GameOver(game models.Game, winner *models.Player, word string)
+	GameDeleted(gameID uint)
+	StateChanged(game models.Game, from, to models.GameState, at time.Time)
+	// Scoreboard is the running score after each round of a match
+	Scoreboard(game models.Game, board Scoreboard)
+	MatchOver(game models.Game, board Scoreboard)
+	// Deadline is sent when a race round starts, RoundTick while it runs
+	Deadline(game models.Game, deadline time.Time)
+	RoundTick(game models.Game, deadline time.Time, left time.Duration)
+	// PlayersEliminated is sent when a round knocks players out of a battle royale
+	PlayersEliminated(game models.Game, players []models.Player)
+	TeamsChanged(game models.Game)
+}
+
+// DefaultCountdown is how long players get to prepare before a round starts
+var DefaultCountdown = 3 * time.Second
+
+// DefaultRoundTick is how often the time left in a race round is broadcast
+var DefaultRoundTick = 10 * time.Second
+
+// DefaultRoundBreak is how long the results of a round show before the next
+// round of the match counts down
+var DefaultRoundBreak = 5 * time.Second
+
+// Engine runs game actions: it loads the game from the Store, applies the
+// rules and saves the result, then reports it through Events. It is the same
+// for every transport
+type Engine struct {
+	Store  Store
+	Events Events
+	// Online reports whether a player has the game open, everyone counts as
+	// online when nil
+	Online func(gameID uint, username string) bool
+	// Now is the clock, time.Now when nil
+	Now func() time.Time
+	// Countdown before each round, DefaultCountdown when zero. Negative
+	// starts rounds straight away
+	Countdown time.Duration
+	// RoundBreak between the rounds of a match, DefaultRoundBreak when zero.
+	// Negative counts down to the next round straight away
+	RoundBreak time.Duration
+	// RoundTick between clock updates of a race round, DefaultRoundTick when zero
+	RoundTick time.Duration
+	// AfterFunc schedules the end of a countdown, time.AfterFunc when nil
+	AfterFunc func(d time.Duration, f func())
+}
+
+func (e *Engine) now() time.Time {
+	if e.Now != nil {
+		return e.Now()
+	}
+	return time.Now()
+}
+
+func (e *Engine) countdown() time.Duration {
+	if e.Countdown == 0 {
+		return DefaultCountdown
+	}
+	if e.Countdown < 0 {
+		return 0
+	}
+	return e.Countdown
+}
+
+func (e *Engine) roundBreak() time.Duration {
+	if e.RoundBreak == 0 {
+		return DefaultRoundBreak
+	}
+	if e.RoundBreak < 0 {
+		return 0
+	}
+	return e.RoundBreak
+}
+
+func (e *Engine) roundTick() time.Duration {
+	if e.RoundTick <= 0 {
+		return DefaultRoundTick
+	}
+	return e.RoundTick
+}
+
+func (e *Engine) after(d time.Duration, f func()) {
+	if e.AfterFunc != nil {
+		e.AfterFunc(d, f)
+		return
+	}
+	time.AfterFunc(d, f)
+}
+
+func (e *Engine) online(gameID uint) func(string) bool {
+	return func(username string) bool {
+		return e.Online == nil || e.Online(gameID, username)
+	}
+}
+
+// action is one engine call. It runs in a transaction that holds the row
+// locks of everything it reads, and its events go out once it is committed
+type action struct {
+	store  Store
+	events []func()
+}
+
+func (a *action) emit(event func()) {
+	a.events = append(a.events, event)
+}
+
+// run executes fn atomically and sends its events if it succeeded
+func (e *Engine) run(fn func(a *action) error) error {
+	a := &action{}
+	err := e.Store.Transaction(func(store Store) error {
+		a.store = store
+		a.events = nil
+		return fn(a)
+	})
+	if err != nil {
+		return err
+	}
+
+	for _, event := range a.events {
+		event()
+	}
+	return nil
+}
+
+// Games are always locked before players so concurrent actions can't deadlock
+func (e *Engine) game(a *action, id uint) (models.Game, error) {
+	game, err := a.store.Game(id)
+	if errors.Is(err, ErrNotFound) {
+		return game, notFound("Game not found")
+	}
+	if err != nil {
+		return game, internal("Failed to fetch game")
+	}
+	return game, nil
+}
+
+func (e *Engine) player(a *action, username string) (models.Player, error) {
+	player, err := a.store.Player(username)
+	if err != nil {
+		return player, internal("Failed to fetch user")
+	}
+	return player, nil
+}
+
+// announce emits the state changes of an action once they are saved
+func (e *Engine) announce(a *action, game models.Game, changes ...StateChange) {
+	for _, change := range changes {
+		change := change
+		a.emit(func() { e.Events.StateChanged(game, change.From, change.To, change.At) })
+	}
+}
+
+// Create makes a new lobby with the player as its admin
+func (e *Engine) Create(username string, options Options) (models.Game, error) {
+	options, err := CheckOptions(options)
+	if err != nil {
+		return models.Game{}, err
+	}
+
+	var game models.Game
+	err = e.run(func(a *action) error {
+		user, err := e.player(a, username)
+		if err != nil {
+			return err
+		}
+
+		if user.GameID != 0 {
+			return invalid("You are already in a game")
+		}
+
+		game = models.Game{
+			State:          models.GameStateLobby,
+			WordLength:     options.WordLength,
+			HardMode:       options.HardMode,
+			Rounds:         options.Rounds,
+			Mode:           options.Mode,
+			RoundSeconds:   options.RoundSeconds,
+			SharedAttempts: options.SharedAttempts,
+			Players:        []models.Player{user},
+		}
+
+		if err := a.store.CreateGame(&game, &user); err != nil {
+			return internal("Failed to create a game")
+		}
+		game.Players[0] = user
+
+		// The admin starts out on the first team, everyone joining after
+		// them fills up the smallest one
+		if IsTeams(game) {
+			if err := a.store.SetTeams(game, map[uint]int{user.ID: 1}); err != nil {
+				return internal("Failed to assign a team")
+			}
+			game.Teams = map[uint]int{user.ID: 1}
+		}
+		return nil
+	})
+
+	return game, err
+}
+
+// Join adds the player to an existing game
+func (e *Engine) Join(username string, gameID uint) (models.Game, error) {
+	var game models.Game
+	err := e.run(func(a *action) error {
+		var err error
+		if game, err = e.game(a, gameID); err != nil {
+			return err
+		}
+
+		user, err := e.player(a, username)
+		if err != nil {
+			return err
+		}
+
+		if user.GameID == gameID {
+			return invalid("You are already in this game")
+		}
+
+		if user.GameID != 0 {
+			return invalid("You are already in a game")
+		}
+
+		if err := CheckJoin(game, user); err != nil {
+			return err
+		}
+
+		if err := a.store.AddPlayer(&game, &user); err != nil {
+			return internal("Failed to update user as game player")
+		}
+
+		// Newcomers to a team game fill up the smallest team
+		if IsTeams(game) {
+			team := AutoTeam(game)
+			if err := a.store.SetTeams(game, map[uint]int{user.ID: team}); err != nil {
+				return internal("Failed to assign a team")
+			}
+			if game.Teams == nil {
+				game.Teams = make(map[uint]int)
+			}
+			game.Teams[user.ID] = team
+		}
+		game.Players = append(game.Players, user)
+
+		joined := game
+		a.emit(func() { e.Events.PlayerJoined(joined) })
+		return nil
+	})
+
+	return game, err
+}
+
+// SetTeams lets the admin move players between teams, or balance the teams
+// automatically when no assignments are given
+func (e *Engine) SetTeams(username string, gameID uint, assignments map[string]int) (models.Game, error) {
+	var game models.Game
+	err := e.run(func(a *action) error {
+		var err error
+		if game, err = e.game(a, gameID); err != nil {
+			return err
+		}
+
+		teams, err := AssignTeams(game, username, assignments)
+		if err != nil {
+			return err
+		}
+
+		if err := a.store.SetTeams(game, teams); err != nil {
+			return internal("Failed to update teams")
+		}
+		game.Teams = teams
+
+		changed := game
+		a.emit(func() { e.Events.TeamsChanged(changed) })
+		return nil
+	})
+
+	return game, err
+}
+
+// Start counts down to a new round, with the admin's custom word if they gave
+// one. Guessing opens when the countdown is over. Between the rounds of a
+// match it skips the rest of the break, otherwise it starts a new match
+func (e *Engine) Start(username string, gameID uint, customWord string) (models.Game, error) {
+	var game models.Game
+	var countdownAt time.Time
+	err := e.run(func(a *action) error {
+		var err error
+		if game, err = e.game(a, gameID); err != nil {
+			return err
+		}
+
+		if _, err := e.player(a, username); err != nil {
+			return err
+		}
+
+		word, err := PrepareStart(game, username, customWord)
+		if err != nil {
+			return err
+		}
+
+		custom := word != ""
+		if !custom {
+			// The admin knows the words they scheduled, so they host those
+			// rounds as game master instead of guessing
+			if custom, err = a.store.Scheduled(game); err != nil {
+				return internal("Failed to fetch the word schedule")
+			}
+			if custom {
+				if err := CheckHost(game); err != nil {
+					return err
+				}
+			}
+			if word, err = a.store.PickWord(game); err != nil {
+				return internal("Failed to pick a word")
+			}
+		}
+
+		change, err := Countdown(&game, username, word, custom, e.now())
+		if err != nil {
+			return err
+		}
+
+		if err := a.store.SaveGame(&game); err != nil {
+			return internal("Failed to update game")
+		}
+		e.announce(a, game, change)
+		countdownAt = change.At
+		return nil
+	})
+	if err != nil {
+		return models.Game{}, err
+	}
+
+	return e.scheduleBegin(game, countdownAt)
+}
+
+// scheduleBegin opens guessing once the countdown that started at countdownAt is over
+func (e *Engine) scheduleBegin(game models.Game, countdownAt time.Time) (models.Game, error) {
+	if e.countdown() == 0 {
+		return e.begin(game.ID, countdownAt)
+	}
+	e.after(e.countdown(), func() {
+		if _, err := e.begin(game.ID, countdownAt); err != nil {
+			log.Printf("Failed to start the round in game %d: %v", game.ID, err)
+		}
+	})
+
+	return game, nil
+}
+
+// next counts down to the next round of the match once the break after the
+// round that ended at roundOverAt is over, unless the admin already started it
+func (e *Engine) next(gameID uint, roundOverAt time.Time) (models.Game, error) {
+	var game models.Game
+	var countdownAt time.Time
+	err := e.run(func(a *action) error {
+		var err error
+		if game, err = e.game(a, gameID); err != nil {
+			return err
+		}
+
+		if game.State != models.GameStateRoundOver || !game.StateChangedAt[models.GameStateRoundOver].Equal(roundOverAt) {
+			return nil
+		}
+
+		// Players may have left during the break, a match that can't go on
+		// ends with the scores so far
+		scheduled, err := a.store.Scheduled(game)
+		if err != nil {
+			return internal("Failed to fetch the word schedule")
+		}
+		err = CheckNextRound(game)
+		if err == nil && scheduled {
+			err = CheckHost(game)
+		}
+		if err != nil {
+			change, board, err := Finish(&game, e.now())
+			if err != nil {
+				return err
+			}
+			if err := a.store.SaveGame(&game); err != nil {
+				return internal("Failed to update game")
+			}
+			e.announce(a, game, change)
+			finished := game
+			a.emit(func() { e.Events.MatchOver(finished, board) })
+			return nil
+		}
+
+		word, err := a.store.PickWord(game)
+		if err != nil {
+			return internal("Failed to pick a word")
+		}
+
+		var host string
+		if scheduled {
+			host = findAdmin(game).Username
+		}
+		change, err := Countdown(&game, host, word, scheduled, e.now())
+		if err != nil {
+			return err
+		}
+
+		if err := a.store.SaveGame(&game); err != nil {
+			return internal("Failed to update game")
+		}
+		e.announce(a, game, change)
+		countdownAt = change.At
+		return nil
+	})
+	if err != nil || countdownAt.IsZero() {
+		return game, err
+	}
+
+	return e.scheduleBegin(game, countdownAt)
+}
+
+// begin opens guessing after the countdown that started at countdownAt, unless
+// that countdown was called off or replaced in the meantime
+func (e *Engine) begin(gameID uint, countdownAt time.Time) (models.Game, error) {
+	var game models.Game
+	err := e.run(func(a *action) error {
+		var err error
+		if game, err = e.game(a, gameID); err != nil {
+			return err
+		}
+
+		if game.State != models.GameStateCountdown || !game.StateChangedAt[models.GameStateCountdown].Equal(countdownAt) {
+			return nil
+		}
+
+		change, err := Begin(&game, e.now())
+		if err != nil {
+			return err
+		}
+
+		if err := a.store.SaveGame(&game); err != nil {
+			return internal("Failed to update game")
+		}
+
+		e.announce(a, game, change)
+		started := game
+		a.emit(func() { e.Events.GameStarted(started) })
+
+		if game.Deadline != nil {
+			deadline := *game.Deadline
+			a.emit(func() {
+				e.Events.Deadline(started, deadline)
+				e.scheduleTick(started.ID, change.At, deadline)
+			})
+		}
+		return nil
+	})
+
+	return game, err
+}
+
+// scheduleTick wakes the clock of the race round that began at beganAt at
+// its next tick, or when it runs out
+func (e *Engine) scheduleTick(gameID uint, beganAt, deadline time.Time) {
+	wait := deadline.Sub(e.now())
+	if wait > e.roundTick() {
+		wait = e.roundTick()
+	}
+	e.after(wait, func() {
+		if err := e.tick(gameID, beganAt); err != nil {
+			log.Printf("Failed to run the round clock in game %d: %v", gameID, err)
+		}
+	})
+}
+
+// tick broadcasts the time left in a race round and ends it when time is up.
+// The clock stops once the round it was started for is over
+func (e *Engine) tick(gameID uint, beganAt time.Time) error {
+	var game models.Game
+	var running, tick bool
+	err := e.run(func(a *action) error {
+		var err error
+		if game, err = a.store.Game(gameID); err != nil {
+			return err
+		}
+
+		if game.State != models.GameStateInProgress || !game.StateChangedAt[models.GameStateInProgress].Equal(beganAt) {
+			return nil
+		}
+
+		left, timed := TimeLeft(game, e.now())
+		if !timed {
+			return nil
+		}
+		if left > 0 {
+			running = true
+			if tick = Tick(&game, e.now(), e.roundTick()); tick {
+				return a.store.SaveGame(&game)
+			}
+			return nil
+		}
+
+		winner := FirstSolver(game, game.Guesses)
+		return e.endRound(a, game, winner, models.RoundOutcomeTimeUp)
+	})
+	if err != nil || !running {
+		return err
+	}
+
+	if tick {
+		left, _ := TimeLeft(game, e.now())
+		e.Events.RoundTick(game, *game.Deadline, left)
+	}
+	e.scheduleTick(gameID, beganAt, *game.Deadline)
+	return nil
+}
+
+// Guess scores a guess, then stores it or ends the round
+func (e *Engine) Guess(username string, gameID uint, guessWord string, attemptNumber uint) (models.Guess, error) {
+	var guess models.Guess
+	err := e.run(func(a *action) error {
+		game, err := e.game(a, gameID)
+		if err != nil {
+			return err
+		}
+
+		user, err := e.player(a, username)
+		if err != nil {
+			return err
+		}
+
+		result, err := Guess(game, user, guessWord, attemptNumber, e.online(game.ID), e.now())
+		if err != nil {
+			return err
+		}
+		guess = result.Guess
+
+		if result.Outcome != "" {
+			// Keep the final guess so it ends up in the round history, it is
+			// not stored as a live guess
+			game.Guesses = append(game.Guesses, result.Guess)
+
+			if err := e.endRound(a, game, result.Winner, result.Outcome); err != nil {
+				return internal("Failed to end the game")
+			}
+			return nil
+		}
+
+		if err := a.store.CreateGuess(&guess); err != nil {
+			if errors.Is(err, ErrDuplicate) {
+				return invalid("You have already made a guess with this attempt number")
+			}
+			return internal("Failed to create a guess")
+		}
+
+		created := guess
+		a.emit(func() { e.Events.NewGuess(game, created) })
+		return nil
+	})
+
+	return guess, err
+}
+
+// Leave takes the player out of the game. The next player becomes admin, an
+// empty game is deleted and a round with one player left is abandoned
+func (e *Engine) Leave(username string, gameID uint) error {
+	return e.run(func(a *action) error {
+		game, err := e.game(a, gameID)
+		if err != nil {
+			return err
+		}
+
+		user, err := e.player(a, username)
+		if err != nil {
+			return err
+		}
+
+		result, err := Leave(&game, username, e.online(game.ID), e.now())
+		if err != nil {
+			return err
+		}
+
+		if err := a.store.RemovePlayer(&game, &user, result.Admin); err != nil {
+			return internal("Failed to remove user from game")
+		}
+
+		if len(result.Changes) > 0 && !result.Empty {
+			if err := a.store.SaveGame(&game); err != nil {
+				return internal("Failed to update game")
+			}
+		}
+		e.announce(a, game, result.Changes...)
+
+		if result.Empty {
+			if err := a.store.DeleteGame(game); err != nil {
+				return internal("Failed to delete game")
+			}
+			a.emit(func() {
+				log.Println("Game deleted successfully: ", game.ID)
+				e.Events.GameDeleted(game.ID)
+			})
+		}
+
+		if result.Abandon {
+			if err := e.endRound(a, game, nil, models.RoundOutcomeAbandoned); err != nil {
+				return internal("Failed to end the game")
+			}
+		}
+
+		// The players left may all be done already, and nobody would send
+		// the guess that ends the round
+		if result.Exhausted {
+			if err := e.endExhausted(a, game); err != nil {
+				return internal("Failed to end the game")
+			}
+		}
+
+		left := game
+		a.emit(func() { e.Events.PlayerLeft(left, user) })
+		return nil
+	})
+}
+
+// EndRoundIfExhausted ends a round once nobody still online has attempts
+// left, for when a player drops off and nobody else can send the guess that
+// would end it
+func (e *Engine) EndRoundIfExhausted(gameID uint) error {
+	return e.run(func(a *action) error {
+		game, err := a.store.Game(gameID)
+		if err != nil {
+			return err
+		}
+
+		if game.State != models.GameStateInProgress || !RoundExhausted(game, game.Guesses, "", e.online(game.ID)) {
+			return nil
+		}
+		return e.endExhausted(a, game)
+	})
+}
+
+// endExhausted ends a round everyone is done with, won if someone solved the word
+func (e *Engine) endExhausted(a *action, game models.Game) error {
+	if winner := FirstSolver(game, game.Guesses); winner != nil {
+		return e.endRound(a, game, winner, models.RoundOutcomeWon)
+	}
+	return e.endRound(a, game, nil, models.RoundOutcomeLost)
+}
+
+// endRound records the round, moves the game on and announces the result and
+// the scores. The next round of a match follows after a break
+func (e *Engine) endRound(a *action, game models.Game, winner *models.Player, outcome models.RoundOutcome) error {
+	end, err := End(&game, winner, outcome, e.now())
+	if err != nil {
+		return err
+	}
+
+	if err := a.store.FinishRound(game, end.Round); err != nil {
+		return err
+	}
+
+	a.emit(func() { e.Events.GameOver(game, winner, end.Word) })
+	e.announce(a, game, end.Changes...)
+
+	if len(end.Eliminated) > 0 {
+		a.emit(func() { e.Events.PlayersEliminated(game, end.Eliminated) })
+	}
+	if outcome != models.RoundOutcomeAbandoned {
+		a.emit(func() { e.Events.Scoreboard(game, end.Scoreboard) })
+	}
+	if end.MatchOver {
+		a.emit(func() { e.Events.MatchOver(game, end.Scoreboard) })
+	}
+
+	if end.NextRound {
+		roundOverAt := game.StateChangedAt[models.GameStateRoundOver]
+		a.emit(func() { e.scheduleNext(game.ID, roundOverAt) })
+	}
+	return nil
+}
+
+// scheduleNext counts down to the next round of the match after the break
+func (e *Engine) scheduleNext(gameID uint, roundOverAt time.Time) {
+	e.after(e.roundBreak(), func() {
+		if _, err := e.next(gameID, roundOverAt); err != nil {
+			log.Printf("Failed to start the next round in game %d: %v", gameID, err)
+		}
+	})
+}
+
+// ResumeTimers restarts the countdowns, race clocks and breaks between rounds
+// that were running when the server stopped. Countdowns and breaks start
+// over, race rounds keep their deadline. Every instance resumes them, the
+// state tokens and shared clock updates keep each step from happening twice
+func (e *Engine) ResumeTimers() error {
+	games, err := e.Store.TimedGames()
+	if err != nil {
+		return err
+	}
+
+	for _, game := range games {
+		switch game.State {
+		case models.GameStateCountdown:
+			if _, err := e.scheduleBegin(game, game.StateChangedAt[models.GameStateCountdown]); err != nil {
+				log.Printf("Failed to start the round in game %d: %v", game.ID, err)
+			}
+		case models.GameStateInProgress:
+			if game.Deadline != nil {
+				e.scheduleTick(game.ID, game.StateChangedAt[models.GameStateInProgress], *game.Deadline)
+			}
+		case models.GameStateRoundOver:
+			if matchUnderway(game) {
+				e.scheduleNext(game.ID, game.StateChangedAt[models.GameStateRoundOver])
+			}
+		}
+	}
+	return nil
+}
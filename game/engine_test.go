@@ -0,0 +1,378 @@
+package game
+
+import (
+	"multiplayer-wordle/models"
+	"reflect"
+	"testing"
+	"time"
+)
+
+// recorder is an Events that notes the name of every event it is told about
+type recorder struct {
+	events []string
+}
+
+func (r *recorder) PlayerJoined(models.Game)              { r.events = append(r.events, "PlayerJoined") }
+func (r *recorder) PlayerLeft(models.Game, models.Player) { r.events = append(r.events, "PlayerLeft") }
+func (r *recorder) GameStarted(models.Game)               { r.events = append(r.events, "GameStarted") }
+func (r *recorder) NewGuess(models.Game, models.Guess)    { r.events = append(r.events, "NewGuess") }
+func (r *recorder) GameDeleted(uint)                      { r.events = append(r.events, "GameDeleted") }
+func (r *recorder) Scoreboard(models.Game, Scoreboard)    { r.events = append(r.events, "Scoreboard") }
+func (r *recorder) MatchOver(models.Game, Scoreboard)     { r.events = append(r.events, "MatchOver") }
+func (r *recorder) Deadline(models.Game, time.Time)       { r.events = append(r.events, "Deadline") }
+func (r *recorder) TeamsChanged(models.Game)              { r.events = append(r.events, "TeamsChanged") }
+func (r *recorder) PlayersEliminated(models.Game, []models.Player) {
+	r.events = append(r.events, "PlayersEliminated")
+}
+func (r *recorder) RoundTick(models.Game, time.Time, time.Duration) {
+	r.events = append(r.events, "RoundTick")
+}
+func (r *recorder) GameOver(models.Game, *models.Player, string) {
+	r.events = append(r.events, "GameOver")
+}
+func (r *recorder) StateChanged(_ models.Game, _, to models.GameState, _ time.Time) {
+	r.events = append(r.events, "StateChanged:"+string(to))
+}
+
+func (r *recorder) saw(event string) bool {
+	for _, seen := range r.events {
+		if seen == event {
+			return true
+		}
+	}
+	return false
+}
+
+// testEngine runs games on an in-memory store with rounds that start without
+// a countdown. Timers only fire when the test calls fire
+type testEngine struct {
+	*Engine
+	store   *memStore
+	events  *recorder
+	pending []func()
+	// Players who don't have the game open
+	offline map[string]bool
+}
+
+func newTestEngine(usernames ...string) *testEngine {
+	te := &testEngine{store: newMemStore(usernames...), events: &recorder{}, offline: make(map[string]bool)}
+	te.Engine = &Engine{
+		Store:     te.store,
+		Events:    te.events,
+		Online:    func(_ uint, username string) bool { return !te.offline[username] },
+		Now:       func() time.Time { return testNow },
+		Countdown: -1,
+		AfterFunc: func(_ time.Duration, f func()) { te.pending = append(te.pending, f) },
+	}
+	return te
+}
+
+// fire runs the timers scheduled so far
+func (te *testEngine) fire() {
+	pending := te.pending
+	te.pending = nil
+	for _, f := range pending {
+		f()
+	}
+}
+
+// lobby creates a game of the creator and has everyone else join it
+func (te *testEngine) lobby(t *testing.T, options Options, creator string, others ...string) models.Game {
+	t.Helper()
+	game, err := te.Create(creator, options)
+	if err != nil {
+		t.Fatalf("Create() error = %v", err)
+	}
+	for _, username := range others {
+		if game, err = te.Join(username, game.ID); err != nil {
+			t.Fatalf("Join(%s) error = %v", username, err)
+		}
+	}
+	return game
+}
+
+func (te *testEngine) game(t *testing.T, id uint) models.Game {
+	t.Helper()
+	game, err := te.store.Game(id)
+	if err != nil {
+		t.Fatalf("Game() error = %v", err)
+	}
+	return game
+}
+
+func TestEngineSolvedRound(t *testing.T) {
+	te := newTestEngine("alice", "bob")
+	game := te.lobby(t, Options{}, "alice", "bob")
+
+	if _, err := te.Start("alice", game.ID, ""); err != nil {
+		t.Fatalf("Start() error = %v", err)
+	}
+	if state := te.game(t, game.ID).State; state != models.GameStateInProgress {
+		t.Fatalf("state = %q after Start, want in progress", state)
+	}
+
+	if _, err := te.Guess("alice", game.ID, "slate", 0); err != nil {
+		t.Fatalf("Guess(slate) error = %v", err)
+	}
+	if _, err := te.Guess("alice", game.ID, "slate", 0); errorCode(err) == "" {
+		t.Errorf("repeated attempt error = %v, want a game error", err)
+	}
+	if _, err := te.Guess("bob", game.ID, "crane", 0); err != nil {
+		t.Fatalf("Guess(crane) error = %v", err)
+	}
+
+	stored := te.game(t, game.ID)
+	if stored.State != models.GameStateFinished {
+		t.Errorf("state = %q after the word was solved, want finished", stored.State)
+	}
+	if len(stored.Guesses) != 0 {
+		t.Errorf("%d live guesses left after the round, want none", len(stored.Guesses))
+	}
+	if len(te.store.rounds) != 1 || te.store.rounds[0].Outcome != models.RoundOutcomeWon {
+		t.Fatalf("rounds = %+v, want one won round", te.store.rounds)
+	}
+
+	want := []string{
+		"PlayerJoined",
+		"StateChanged:" + string(models.GameStateCountdown),
+		"StateChanged:" + string(models.GameStateInProgress),
+		"GameStarted",
+		"NewGuess",
+		"GameOver",
+		"StateChanged:" + string(models.GameStateRoundOver),
+		"StateChanged:" + string(models.GameStateFinished),
+		"Scoreboard",
+		"MatchOver",
+	}
+	if !reflect.DeepEqual(te.events.events, want) {
+		t.Errorf("events = %v, want %v", te.events.events, want)
+	}
+}
+
+func TestEngineLeaveEndsExhaustedRound(t *testing.T) {
+	te := newTestEngine("alice", "bob", "carol")
+	game := te.lobby(t, Options{}, "alice", "bob", "carol")
+
+	if _, err := te.Start("alice", game.ID, ""); err != nil {
+		t.Fatalf("Start() error = %v", err)
+	}
+	for _, username := range []string{"alice", "bob"} {
+		for attempt := uint(0); attempt < 6; attempt++ {
+			if _, err := te.Guess(username, game.ID, "slate", attempt); err != nil {
+				t.Fatalf("Guess(%s, %d) error = %v", username, attempt, err)
+			}
+		}
+	}
+	if state := te.game(t, game.ID).State; state != models.GameStateInProgress {
+		t.Fatalf("state = %q while carol is still guessing, want in progress", state)
+	}
+
+	if err := te.Leave("carol", game.ID); err != nil {
+		t.Fatalf("Leave() error = %v", err)
+	}
+
+	stored := te.game(t, game.ID)
+	if stored.State != models.GameStateFinished {
+		t.Errorf("state = %q once only players out of attempts are left, want finished", stored.State)
+	}
+	if len(te.store.rounds) != 1 || te.store.rounds[0].Outcome != models.RoundOutcomeLost {
+		t.Errorf("rounds = %+v, want one lost round", te.store.rounds)
+	}
+	if !te.events.saw("PlayerLeft") {
+		t.Errorf("events = %v, want PlayerLeft", te.events.events)
+	}
+}
+
+func TestEngineJoin(t *testing.T) {
+	te := newTestEngine("alice", "bob", "carol")
+	game := te.lobby(t, Options{}, "alice", "bob")
+
+	joined, err := te.Join("carol", game.ID)
+	if err != nil {
+		t.Fatalf("Join() error = %v", err)
+	}
+	var usernames []string
+	for _, player := range joined.Players {
+		usernames = append(usernames, player.Username)
+	}
+	if want := []string{"alice", "bob", "carol"}; !reflect.DeepEqual(usernames, want) {
+		t.Errorf("players = %v after joining, want %v", usernames, want)
+	}
+
+	if _, err := te.Join("carol", game.ID); errorCode(err) == "" {
+		t.Errorf("second Join() error = %v, want a game error", err)
+	}
+	if players := te.game(t, game.ID).Players; len(players) != 3 {
+		t.Errorf("%d players stored, want 3", len(players))
+	}
+}
+
+func TestEngineEndRoundIfExhausted(t *testing.T) {
+	te := newTestEngine("alice", "bob", "carol")
+	game := te.lobby(t, Options{}, "alice", "bob", "carol")
+
+	if _, err := te.Start("alice", game.ID, ""); err != nil {
+		t.Fatalf("Start() error = %v", err)
+	}
+	for attempt := uint(0); attempt < 6; attempt++ {
+		if _, err := te.Guess("alice", game.ID, "slate", attempt); err != nil {
+			t.Fatalf("Guess(%d) error = %v", attempt, err)
+		}
+	}
+
+	te.offline["carol"] = true
+	if err := te.EndRoundIfExhausted(game.ID); err != nil {
+		t.Fatalf("EndRoundIfExhausted() error = %v", err)
+	}
+	if state := te.game(t, game.ID).State; state != models.GameStateInProgress {
+		t.Fatalf("state = %q while bob is still guessing, want in progress", state)
+	}
+
+	// Nobody still online can guess anymore
+	te.offline["bob"] = true
+	if err := te.EndRoundIfExhausted(game.ID); err != nil {
+		t.Fatalf("EndRoundIfExhausted() error = %v", err)
+	}
+	if state := te.game(t, game.ID).State; state != models.GameStateFinished {
+		t.Errorf("state = %q once everyone online is done, want finished", state)
+	}
+	if len(te.store.rounds) != 1 || te.store.rounds[0].Outcome != models.RoundOutcomeLost {
+		t.Errorf("rounds = %+v, want one lost round", te.store.rounds)
+	}
+}
+
+func TestEngineLeaveDeletesEmptyGame(t *testing.T) {
+	te := newTestEngine("alice")
+	game := te.lobby(t, Options{}, "alice")
+
+	if err := te.Leave("alice", game.ID); err != nil {
+		t.Fatalf("Leave() error = %v", err)
+	}
+	if _, err := te.store.Game(game.ID); err != ErrNotFound {
+		t.Errorf("Game() error = %v after the last player left, want ErrNotFound", err)
+	}
+	if player, _ := te.store.Player("alice"); player.GameID != 0 {
+		t.Errorf("GameID = %d after leaving, want 0", player.GameID)
+	}
+	if !te.events.saw("GameDeleted") {
+		t.Errorf("events = %v, want GameDeleted", te.events.events)
+	}
+}
+
+func TestEngineTeams(t *testing.T) {
+	te := newTestEngine("alice", "bob", "carol")
+	game := te.lobby(t, Options{Mode: models.GameModeTeams}, "alice", "bob", "carol")
+
+	stored := te.game(t, game.ID)
+	alice, _ := te.store.Player("alice")
+	bob, _ := te.store.Player("bob")
+	if stored.Teams[alice.ID] != 1 {
+		t.Errorf("creator is on team %d, want 1", stored.Teams[alice.ID])
+	}
+	if stored.Teams[bob.ID] != 2 {
+		t.Errorf("first to join is on team %d, want 2", stored.Teams[bob.ID])
+	}
+
+	// Someone without a team can't play the round
+	te.store.teams[game.ID][bob.ID] = 0
+	if _, err := te.Start("alice", game.ID, ""); errorCode(err) == "" {
+		t.Fatalf("Start() error = %v with a player on no team, want a game error", err)
+	}
+
+	if _, err := te.SetTeams("alice", game.ID, nil); err != nil {
+		t.Fatalf("SetTeams() error = %v", err)
+	}
+	if _, err := te.Start("alice", game.ID, ""); err != nil {
+		t.Errorf("Start() error = %v after balancing the teams", err)
+	}
+}
+
+func TestEngineScheduledWord(t *testing.T) {
+	te := newTestEngine("alice", "bob")
+	game := te.lobby(t, Options{}, "alice")
+	te.store.schedule[game.ID] = []string{"plant"}
+
+	if _, err := te.Start("alice", game.ID, ""); errorCode(err) == "" {
+		t.Fatalf("Start() error = %v with nobody to guess the scheduled word, want a game error", err)
+	}
+
+	if _, err := te.Join("bob", game.ID); err != nil {
+		t.Fatalf("Join() error = %v", err)
+	}
+	if _, err := te.Start("alice", game.ID, ""); err != nil {
+		t.Fatalf("Start() error = %v", err)
+	}
+
+	stored := te.game(t, game.ID)
+	alice, _ := te.store.Player("alice")
+	if stored.Word != "plant" {
+		t.Errorf("word = %q, want the scheduled plant", stored.Word)
+	}
+	if stored.GameMasterID == nil || *stored.GameMasterID != alice.ID {
+		t.Errorf("GameMasterID = %v, want the admin %d", stored.GameMasterID, alice.ID)
+	}
+	if _, err := te.Guess("alice", game.ID, "plant", 0); errorCode(err) == "" {
+		t.Errorf("game master Guess() error = %v, want a game error", err)
+	}
+}
+
+func TestEngineMatchEndsWhenPlayersLeaveDuringBreak(t *testing.T) {
+	te := newTestEngine("alice", "bob")
+	game := te.lobby(t, Options{Rounds: 3}, "alice", "bob")
+
+	if _, err := te.Start("alice", game.ID, ""); err != nil {
+		t.Fatalf("Start() error = %v", err)
+	}
+	if _, err := te.Guess("bob", game.ID, "crane", 0); err != nil {
+		t.Fatalf("Guess() error = %v", err)
+	}
+	if state := te.game(t, game.ID).State; state != models.GameStateRoundOver {
+		t.Fatalf("state = %q after the first round, want round over", state)
+	}
+	if len(te.pending) != 1 {
+		t.Fatalf("%d timers pending after the first round, want the break", len(te.pending))
+	}
+
+	if err := te.Leave("bob", game.ID); err != nil {
+		t.Fatalf("Leave() error = %v", err)
+	}
+	te.fire()
+
+	stored := te.game(t, game.ID)
+	if stored.State != models.GameStateFinished {
+		t.Errorf("state = %q after the break with nobody left to play, want finished", stored.State)
+	}
+	if stored.RoundNumber != 1 {
+		t.Errorf("RoundNumber = %d, want the match to stop after round 1", stored.RoundNumber)
+	}
+	if !te.events.saw("MatchOver") {
+		t.Errorf("events = %v, want MatchOver", te.events.events)
+	}
+}
+
+func TestEngineNextRound(t *testing.T) {
+	te := newTestEngine("alice", "bob")
+	game := te.lobby(t, Options{Rounds: 2}, "alice", "bob")
+
+	if _, err := te.Start("alice", game.ID, ""); err != nil {
+		t.Fatalf("Start() error = %v", err)
+	}
+	if _, err := te.Guess("bob", game.ID, "crane", 0); err != nil {
+		t.Fatalf("Guess() error = %v", err)
+	}
+	te.fire()
+
+	stored := te.game(t, game.ID)
+	if stored.State != models.GameStateInProgress || stored.RoundNumber != 2 {
+		t.Fatalf("state = %q round %d after the break, want round 2 in progress", stored.State, stored.RoundNumber)
+	}
+
+	// A timer from the round that is over doesn't move the new one on
+	roundOverAt := stored.StateChangedAt[models.GameStateRoundOver]
+	if _, err := te.next(game.ID, roundOverAt); err != nil {
+		t.Fatalf("next() error = %v", err)
+	}
+	if state := te.game(t, game.ID).State; state != models.GameStateInProgress {
+		t.Errorf("state = %q after a stale timer, want in progress", state)
+	}
+}
@@ -0,0 +1,41 @@
+package game
+
+// ErrorKind tells the transport how to report an Error
+type ErrorKind int
+
+const (
+	// The action breaks a rule or the input is invalid
+	KindInvalid ErrorKind = iota
+	// The game does not exist
+	KindNotFound
+	// Persistence failed, the action may be retried
+	KindInternal
+)
+
+// Error is a failed game action with the message shown to the player
+type Error struct {
+	Kind    ErrorKind
+	Message string
+	Code    string                 // Optional machine readable reason
+	Fields  map[string]interface{} // Optional extra fields for the client
+}
+
+func (e *Error) Error() string {
+	return e.Message
+}
+
+func invalid(message string) *Error {
+	return &Error{Kind: KindInvalid, Message: message}
+}
+
+func invalidCode(message, code string) *Error {
+	return &Error{Kind: KindInvalid, Message: message, Code: code}
+}
+
+func notFound(message string) *Error {
+	return &Error{Kind: KindNotFound, Message: message}
+}
+
+func internal(message string) *Error {
+	return &Error{Kind: KindInternal, Message: message}
+}
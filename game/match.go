@@ -0,0 +1,201 @@
+package game
+
+import (
+	"fmt"
+	"multiplayer-wordle/constants"
+	"multiplayer-wordle/models"
+	"sort"
+	"time"
+)
+
+// MaxRounds is the longest match that can be configured
+const MaxRounds = 10
+
+const (
+	// Points for every attempt a solver has left over, counting the solving one
+	pointsPerAttempt = 100
+	// Solving right away earns up to this much more, less the longer it takes
+	maxSpeedBonus    = 100
+	speedBonusWindow = 2 * time.Minute
+)
+
+// checkRounds validates the match length chosen for a new game, 0 is a single round
+func checkRounds(rounds int) (int, error) {
+	if rounds == 0 {
+		return 1, nil
+	}
+	if rounds < 1 || rounds > MaxRounds {
+		return 0, invalid(fmt.Sprintf("Rounds must be between 1 and %d", MaxRounds))
+	}
+	return rounds, nil
+}
+
+// matchRounds is the length of the game's match, games from before matches play one round
+func matchRounds(game models.Game) int {
+	if game.Rounds < 1 {
+		return 1
+	}
+	return game.Rounds
+}
+
+// MatchOver reports whether the round that just ended was the last of the
+// match, for a battle royale when one player or nobody is left standing
+func MatchOver(game models.Game) bool {
+	if IsElimination(game) {
+		return len(survivors(game)) <= 1
+	}
+	return game.RoundNumber >= matchRounds(game)
+}
+
+// survivors returns the players not knocked out of the battle royale
+func survivors(game models.Game) []models.Player {
+	var players []models.Player
+	for _, player := range game.Players {
+		if !IsEliminated(game, player.ID) {
+			players = append(players, player)
+		}
+	}
+	return players
+}
+
+// Points scores a solved round: fewer attempts and a quicker solve earn more
+func Points(attemptsUsed uint, elapsed time.Duration) int {
+	points := (constants.MaxAttempts - int(attemptsUsed) + 1) * pointsPerAttempt
+	if elapsed < speedBonusWindow {
+		points += int(maxSpeedBonus * (speedBonusWindow - elapsed) / speedBonusWindow)
+	}
+	if points < 0 {
+		return 0
+	}
+	return points
+}
+
+// matchUnderway reports whether the game is between the rounds of a match
+// that has more to play
+func matchUnderway(game models.Game) bool {
+	return currentState(game) == models.GameStateRoundOver && game.RoundNumber > 0 && !MatchOver(game)
+}
+
+// nextRound moves the match on to its next round, or starts a new match when
+// the last one is over or was never started
+func nextRound(game *models.Game) {
+	if matchUnderway(*game) {
+		game.RoundNumber++
+		return
+	}
+	game.RoundNumber = 1
+	game.Scores = make(map[uint]int)
+	game.Eliminated = nil
+}
+
+// ScoreEntry is one player's line on the scoreboard
+type ScoreEntry struct {
+	PlayerID    uint   `json:"playerId"`
+	Username    string `json:"username"`
+	Points      int    `json:"points"`      // Total for the match so far
+	RoundPoints int    `json:"roundPoints"` // Earned in the last round
+	Eliminated  bool   `json:"eliminated,omitempty"`
+}
+
+// Scoreboard is the running score of a match, highest first
+type Scoreboard struct {
+	GameID uint `json:"gameId"`
+	Round  int  `json:"round"`
+	// 0 for a battle royale, it lasts until one player is left
+	Rounds  int          `json:"rounds"`
+	Entries []ScoreEntry `json:"entries"`
+	// Match points of each team in a team game
+	TeamPoints map[int]int `json:"teamPoints,omitempty"`
+	// Set once the match is over, more than one on a tie
+	WinnerIDs []uint `json:"winnerIds,omitempty"`
+}
+
+// BuildScoreboard ranks the players still in the game by their match points
+func BuildScoreboard(game models.Game, round models.Round) Scoreboard {
+	return buildScoreboard(game, round, MatchOver(game))
+}
+
+// buildScoreboard ranks the players, and picks the winners when the match is over
+func buildScoreboard(game models.Game, round models.Round, over bool) Scoreboard {
+	board := Scoreboard{
+		GameID: game.ID,
+		Round:  game.RoundNumber,
+		Rounds: matchRounds(game),
+	}
+	if IsElimination(game) {
+		board.Rounds = 0
+	}
+
+	roundPoints := make(map[uint]int)
+	for _, player := range round.Players {
+		roundPoints[player.PlayerID] = player.Points
+	}
+
+	for _, player := range game.Players {
+		board.Entries = append(board.Entries, ScoreEntry{
+			PlayerID:    player.ID,
+			Username:    player.Username,
+			Points:      game.Scores[player.ID],
+			RoundPoints: roundPoints[player.ID],
+			Eliminated:  IsEliminated(game, player.ID),
+		})
+	}
+	sort.SliceStable(board.Entries, func(i, j int) bool {
+		return board.Entries[i].Points > board.Entries[j].Points
+	})
+
+	if IsTeams(game) {
+		board.TeamPoints = make(map[int]int)
+		for _, entry := range board.Entries {
+			if team := game.Teams[entry.PlayerID]; team != 0 {
+				board.TeamPoints[team] += entry.Points
+			}
+		}
+	}
+
+	// The last one standing wins a battle royale, the team with the most points
+	// a team game, and the player with the most points any other match
+	if over && IsElimination(game) {
+		for _, player := range survivors(game) {
+			board.WinnerIDs = append(board.WinnerIDs, player.ID)
+		}
+	} else if over && IsTeams(game) {
+		best := 0
+		for _, points := range board.TeamPoints {
+			if points > best {
+				best = points
+			}
+		}
+		for _, entry := range board.Entries {
+			if team := game.Teams[entry.PlayerID]; team != 0 && board.TeamPoints[team] == best {
+				board.WinnerIDs = append(board.WinnerIDs, entry.PlayerID)
+			}
+		}
+	} else if over && len(board.Entries) > 0 {
+		for _, entry := range board.Entries {
+			if entry.Points == board.Entries[0].Points {
+				board.WinnerIDs = append(board.WinnerIDs, entry.PlayerID)
+			}
+		}
+	}
+	return board
+}
+
+// CheckNextRound makes sure the match can go on once the break between its
+// rounds is over, players may have left in the meantime
+func CheckNextRound(game models.Game) error {
+	if !matchUnderway(game) {
+		return invalid("The match is over")
+	}
+	return checkPlayers(game, 0)
+}
+
+// Finish ends a match between its rounds when it can't go on, the points
+// scored so far decide who wins
+func Finish(game *models.Game, now time.Time) (StateChange, Scoreboard, error) {
+	change, err := Transition(game, models.GameStateFinished, now)
+	if err != nil {
+		return change, Scoreboard{}, err
+	}
+	return change, buildScoreboard(*game, models.Round{}, true), nil
+}
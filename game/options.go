@@ -0,0 +1,70 @@
+package game
+
+import (
+	"fmt"
+	"multiplayer-wordle/constants"
+	"multiplayer-wordle/models"
+)
+
+// Options configure a new game
+type Options struct {
+	WordLength int // DefaultWordLength when zero
+	HardMode   bool
+	Rounds     int             // Rounds per match, one when zero. Ignored by a battle royale
+	Mode       models.GameMode // Classic when empty
+	// Time limit of each race round, DefaultRoundSeconds when zero
+	RoundSeconds int
+	// Attempts a co-op team shares, MaxAttempts when zero
+	SharedAttempts int
+}
+
+// CheckOptions validates the options of a new game and fills in the defaults
+func CheckOptions(options Options) (Options, error) {
+	if options.WordLength == 0 {
+		options.WordLength = constants.DefaultWordLength
+	}
+
+	if options.WordLength < constants.MinWordLength || options.WordLength > constants.MaxWordLength {
+		return options, invalid(fmt.Sprintf("Word length must be between %d and %d", constants.MinWordLength, constants.MaxWordLength))
+	}
+
+	rounds, err := checkRounds(options.Rounds)
+	if err != nil {
+		return options, err
+	}
+	options.Rounds = rounds
+
+	if options.Mode != models.GameModeCoop {
+		options.SharedAttempts = 0
+	}
+
+	switch options.Mode {
+	case "", models.GameModeClassic:
+		options.Mode = models.GameModeClassic
+		options.RoundSeconds = 0
+	case models.GameModeRace:
+		if options.RoundSeconds == 0 {
+			options.RoundSeconds = DefaultRoundSeconds
+		}
+		if options.RoundSeconds < MinRoundSeconds || options.RoundSeconds > MaxRoundSeconds {
+			return options, invalid(fmt.Sprintf("Round time must be between %d and %d seconds", MinRoundSeconds, MaxRoundSeconds))
+		}
+	case models.GameModeElimination:
+		// A battle royale goes on until one player is left, however many rounds it takes
+		options.RoundSeconds = 0
+	case models.GameModeCoop:
+		options.RoundSeconds = 0
+		if options.SharedAttempts == 0 {
+			options.SharedAttempts = constants.MaxAttempts
+		}
+		if options.SharedAttempts < 1 || options.SharedAttempts > MaxSharedAttempts {
+			return options, invalid(fmt.Sprintf("Shared attempts must be between 1 and %d", MaxSharedAttempts))
+		}
+	case models.GameModeTeams:
+		options.RoundSeconds = 0
+	default:
+		return options, invalid(fmt.Sprintf("Unknown game mode %q", options.Mode))
+	}
+
+	return options, nil
+}
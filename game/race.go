@@ -0,0 +1,103 @@
+package game
+
+import (
+	"multiplayer-wordle/constants"
+	"multiplayer-wordle/models"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Race rounds are timed by the server, the clients only display the clock
+const (
+	DefaultRoundSeconds = 120
+	MinRoundSeconds     = 30
+	MaxRoundSeconds     = 600
+
+	// Race solvers earn up to this much more for the time they had left
+	maxRaceBonus = 500
+)
+
+// IsRace reports whether the game's rounds are played against the clock
+func IsRace(game models.Game) bool {
+	return game.Mode == models.GameModeRace
+}
+
+// roundLimit is how long a race round lasts
+func roundLimit(game models.Game) time.Duration {
+	seconds := game.RoundSeconds
+	if seconds == 0 {
+		seconds = DefaultRoundSeconds
+	}
+	return time.Duration(seconds) * time.Second
+}
+
+// TimeLeft reports how long the running round has left, false when it has no clock
+func TimeLeft(game models.Game, now time.Time) (time.Duration, bool) {
+	if game.Deadline == nil {
+		return 0, false
+	}
+	if left := game.Deadline.Sub(now); left > 0 {
+		return left, true
+	}
+	return 0, true
+}
+
+// Tick reports whether a clock update of the race round is due and records
+// it. Every instance that resumed the round runs its clock, only one of them
+// announces each update
+func Tick(game *models.Game, now time.Time, every time.Duration) bool {
+	if game.TickedAt != nil && now.Sub(*game.TickedAt) < every {
+		return false
+	}
+	game.TickedAt = &now
+	return true
+}
+
+// RacePoints scores a solved race round: every attempt left over counts, and
+// so does every second left on the clock
+func RacePoints(attemptsUsed uint, elapsed, limit time.Duration) int {
+	points := (constants.MaxAttempts - int(attemptsUsed) + 1) * pointsPerAttempt
+	if limit > 0 && elapsed < limit {
+		points += int(maxRaceBonus * (limit - elapsed) / limit)
+	}
+	if points < 0 {
+		return 0
+	}
+	return points
+}
+
+// roundPoints scores a solved round by the game's mode
+func roundPoints(game models.Game, attemptsUsed uint, elapsed time.Duration) int {
+	if IsRace(game) {
+		return RacePoints(attemptsUsed, elapsed, roundLimit(game))
+	}
+	return Points(attemptsUsed, elapsed)
+}
+
+// solves reports whether the guess found the word
+func solves(guess models.Guess) bool {
+	return guess.Feedback != "" && strings.Count(guess.Feedback, "2") == len(guess.Feedback)
+}
+
+// FirstSolver returns the player who solved the word first, nil when nobody has
+func FirstSolver(game models.Game, guesses []models.Guess) *models.Player {
+	var solved []models.Guess
+	for _, guess := range guesses {
+		if solves(guess) {
+			solved = append(solved, guess)
+		}
+	}
+	sort.SliceStable(solved, func(i, j int) bool {
+		return solved[i].CreatedAt.Before(solved[j].CreatedAt)
+	})
+
+	for _, guess := range solved {
+		for i := range game.Players {
+			if game.Players[i].ID == guess.PlayerID {
+				return &game.Players[i]
+			}
+		}
+	}
+	return nil
+}
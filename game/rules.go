@@ -0,0 +1,633 @@
+package game
+
+import (
+	"fmt"
+	"multiplayer-wordle/constants"
+	"multiplayer-wordle/dictionary"
+	"multiplayer-wordle/models"
+	"strings"
+	"time"
+)
+
+// MaxPlayers is the largest lobby allowed
+const MaxPlayers = 8
+
+// The rules below only look at and change the game they are given, they
+// never touch the database or the network
+
+// Score compares a guess with the word and returns whether it is correct and
+// the feedback per letter: 2 right place, 1 wrong place, 0 not in the word
+func Score(guessWord, word string) (bool, string) {
+	letters := strings.Split(word, "")
+	guessLetters := strings.Split(guessWord, "")
+
+	if guessWord == word {
+		return true, strings.Repeat("2", len(word))
+	}
+
+	feedback := []rune(strings.Repeat("0", len(word)))
+
+	// First pass: check for correct positions
+	for i := range letters {
+		if letters[i] == guessLetters[i] {
+			feedback[i] = '2'
+			letters[i] = "" // Mark this letter as used
+		}
+	}
+
+	// Second pass: check for present but wrong positions
+	for i := range guessLetters {
+		if feedback[i] == '0' {
+			for j := range letters {
+				if guessLetters[i] == letters[j] {
+					feedback[i] = '1'
+					letters[j] = ""
+					break
+				}
+			}
+		}
+	}
+
+	return false, string(feedback)
+}
+
+// IsGameMaster reports whether a player is hosting the round with a custom word
+func IsGameMaster(game models.Game, playerID uint) bool {
+	return game.GameMasterID != nil && *game.GameMasterID == playerID
+}
+
+// findPlayer returns the player with the username, or nil if they aren't in the game
+func findPlayer(game models.Game, username string) *models.Player {
+	for i := range game.Players {
+		if game.Players[i].Username == username {
+			return &game.Players[i]
+		}
+	}
+	return nil
+}
+
+// findAdmin returns the lobby admin, or nil if the game has none
+func findAdmin(game models.Game) *models.Player {
+	for i := range game.Players {
+		if game.Players[i].IsAdmin {
+			return &game.Players[i]
+		}
+	}
+	return nil
+}
+
+// CheckHardMode returns a message naming the broken rule, or an empty string
+// if the guess respects every green and yellow letter from the previous guesses
+func CheckHardMode(guessWord string, previousGuesses []models.Guess) string {
+	for _, previous := range previousGuesses {
+		// Green letters must stay in place
+		for i, mark := range previous.Feedback {
+			if mark == '2' && i < len(guessWord) && guessWord[i] != previous.GuessWord[i] {
+				return fmt.Sprintf("%s letter must be %s", ordinal(i+1), strings.ToUpper(string(previous.GuessWord[i])))
+			}
+		}
+
+		// Yellow letters must be reused, as many times as they were revealed
+		required := make(map[byte]int)
+		for i, mark := range previous.Feedback {
+			if mark == '1' || mark == '2' {
+				required[previous.GuessWord[i]]++
+			}
+		}
+		for i, mark := range previous.Feedback {
+			letter := previous.GuessWord[i]
+			if mark == '1' && strings.Count(guessWord, string(letter)) < required[letter] {
+				return fmt.Sprintf("Guess must contain %s", strings.ToUpper(string(letter)))
+			}
+		}
+	}
+	return ""
+}
+
+func ordinal(n int) string {
+	switch n {
+	case 1:
+		return "1st"
+	case 2:
+		return "2nd"
+	case 3:
+		return "3rd"
+	default:
+		return fmt.Sprintf("%dth", n)
+	}
+}
+
+// RoundExhausted reports whether every guessing player still online, plus the
+// one guessing right now, has run out of attempts or solved the word. Players
+// who closed their tab or never opened the game don't hold the round open
+func RoundExhausted(game models.Game, guesses []models.Guess, guesser string, online func(username string) bool) bool {
+	// A co-op team is done when its shared pool is used up
+	if IsCoop(game) {
+		return len(guesses) >= sharedAttempts(game)
+	}
+
+	attempts := make(map[uint]int)
+	solved := make(map[uint]bool)
+	for _, guess := range guesses {
+		attempts[guess.PlayerID]++
+		if solves(guess) {
+			solved[guess.PlayerID] = true
+		}
+	}
+
+	active := 0
+	for _, player := range game.Players {
+		if IsGameMaster(game, player.ID) || IsEliminated(game, player.ID) {
+			continue
+		}
+		if player.Username != guesser && !online(player.Username) {
+			continue
+		}
+		if attempts[player.ID] < constants.MaxAttempts && !solved[player.ID] {
+			return false
+		}
+		active++
+	}
+	// Nobody online is not the same as everybody done
+	return active > 0
+}
+
+// CheckJoin validates that a player may join the game
+func CheckJoin(game models.Game, player models.Player) error {
+	if !Joinable(game) {
+		return invalid("Game is not in lobby state")
+	}
+	if len(game.Players)+1 > MaxPlayers {
+		return invalid("Game is full")
+	}
+	if findPlayer(game, player.Username) != nil {
+		return invalid("You are already in this game")
+	}
+	// Nobody drops into a battle royale between its rounds
+	if IsElimination(game) && matchUnderway(game) {
+		return invalid("The battle royale is already underway")
+	}
+	return nil
+}
+
+// PrepareStart checks that the player may start the round and returns the
+// validated custom word, empty when the word should be picked for them
+func PrepareStart(game models.Game, username, customWord string) (string, error) {
+	player := findPlayer(game, username)
+	if player == nil {
+		return "", invalid("You are not in this game")
+	}
+
+	if !player.IsAdmin {
+		return "", invalid("You are not the admin")
+	}
+
+	if game.State == models.GameStateInProgress || game.State == models.GameStateCountdown {
+		return "", invalid("Game is already in progress")
+	}
+
+	if !CanTransition(currentState(game), models.GameStateCountdown) {
+		return "", invalid("The game cannot be started right now")
+	}
+
+	custom := strings.TrimSpace(customWord) != ""
+
+	// The admin watches as game master when they pick the word
+	var gameMasterID uint
+	if custom {
+		gameMasterID = player.ID
+	}
+	if err := checkPlayers(game, gameMasterID); err != nil {
+		return "", err
+	}
+
+	// The admin can optionally pick the word and watch as game master
+	if !custom {
+		return "", nil
+	}
+
+	word, err := dictionary.Validate(customWord)
+	if err != nil || len(word) != game.WordLength {
+		return "", invalidCode(fmt.Sprintf("The word must be a valid %d letter word", game.WordLength), "not_a_word")
+	}
+
+	if len(game.Players) < 2 {
+		return "", invalid("You need at least one other player to host a custom word")
+	}
+
+	return word, nil
+}
+
+// CheckHost makes sure the admin can watch the round as game master, which
+// they do when it plays a word they scheduled
+func CheckHost(game models.Game) error {
+	admin := findAdmin(game)
+	if admin == nil {
+		return invalid("The game has no admin")
+	}
+	if len(game.Players) < 2 {
+		return invalid("You need at least one other player to play a scheduled word")
+	}
+	return checkPlayers(game, admin.ID)
+}
+
+// checkPlayers makes sure enough players are left to play the round, the game
+// master, if there is one, only watches
+func checkPlayers(game models.Game, gameMasterID uint) error {
+	if IsElimination(game) {
+		// Between rounds only the survivors play on, a new match starts with everyone
+		players := game.Players
+		if matchUnderway(game) {
+			players = survivors(game)
+		}
+		guessers := 0
+		for _, player := range players {
+			if player.ID != gameMasterID {
+				guessers++
+			}
+		}
+		if guessers < 2 {
+			return invalid("You need at least two players for a battle royale")
+		}
+	}
+
+	if IsTeams(game) {
+		if err := checkTeams(game, gameMasterID); err != nil {
+			return err
+		}
+	}
+
+	// A match that was played against others is over once they have all left
+	if matchUnderway(game) && len(game.Scores) > 1 && len(game.Players) < 2 {
+		return invalid("Everyone else has left the match")
+	}
+	return nil
+}
+
+// Countdown sets up the next round with its word and starts the countdown to
+// it. A custom word makes the admin the game master
+func Countdown(game *models.Game, username, word string, custom bool, now time.Time) (StateChange, error) {
+	if !CanTransition(currentState(*game), models.GameStateCountdown) {
+		return Transition(game, models.GameStateCountdown, now)
+	}
+	nextRound(game)
+
+	change, err := Transition(game, models.GameStateCountdown, now)
+	if err != nil {
+		return change, err
+	}
+
+	// Guesses are cleared when a round ends, none carry over
+	game.Guesses = nil
+	game.Word = word
+	game.GameMasterID = nil
+	game.Deadline = nil
+
+	if custom {
+		if player := findPlayer(*game, username); player != nil {
+			game.GameMasterID = &player.ID
+		}
+	}
+	return change, nil
+}
+
+// Begin lets the players start guessing once the countdown is over, and
+// starts the clock of a race round
+func Begin(game *models.Game, now time.Time) (StateChange, error) {
+	change, err := Transition(game, models.GameStateInProgress, now)
+	if err != nil {
+		return change, err
+	}
+	game.StartedAt = &now
+	game.TickedAt = nil
+
+	if IsRace(*game) {
+		deadline := now.Add(roundLimit(*game))
+		game.Deadline = &deadline
+	}
+	return change, nil
+}
+
+// GuessResult is a scored guess and how it left the round
+type GuessResult struct {
+	Guess models.Guess
+	// Empty while the round goes on
+	Outcome models.RoundOutcome
+	// Who won the round, when it was won
+	Winner *models.Player
+}
+
+// Guess validates and scores a guess. The game is not changed, the caller
+// stores the guess or ends the round depending on the outcome. In classic
+// mode the first solve wins the round, in a race everyone keeps going until
+// they all solved the word or ran out of attempts
+func Guess(game models.Game, player models.Player, guessWord string, attemptNumber uint, online func(username string) bool, now time.Time) (GuessResult, error) {
+	if game.State != models.GameStateInProgress {
+		return GuessResult{}, invalid("Game is not in progress")
+	}
+
+	// The server's clock decides, a late guess doesn't count however it got here
+	if left, timed := TimeLeft(game, now); timed && left == 0 {
+		return GuessResult{}, invalidCode("Time is up for this round", "time_up")
+	}
+
+	guessWord, err := dictionary.Normalize(guessWord)
+	if err != nil {
+		return GuessResult{}, invalidCode("The guess word must only contain letters", "invalid_characters")
+	}
+
+	if len(guessWord) != game.WordLength || guessWord == "" {
+		return GuessResult{}, invalid(fmt.Sprintf("The guess word must be exactly %d letters", game.WordLength))
+	}
+
+	// Rejected before anything is saved so the attempt is not consumed
+	if !dictionary.IsWord(guessWord) {
+		notAWord := invalidCode("Not a valid word", "not_a_word")
+		notAWord.Fields = map[string]interface{}{"guessWord": guessWord}
+		return GuessResult{}, notAWord
+	}
+
+	if findPlayer(game, player.Username) == nil {
+		return GuessResult{}, invalid("You are not in this game")
+	}
+
+	if IsGameMaster(game, player.ID) {
+		return GuessResult{}, invalid("The game master cannot guess")
+	}
+
+	if IsEliminated(game, player.ID) {
+		return GuessResult{}, invalidCode("You have been eliminated", "eliminated")
+	}
+
+	var previousGuesses []models.Guess
+	if IsCoop(game) {
+		if err := checkSharedAttempt(game, attemptNumber); err != nil {
+			return GuessResult{}, err
+		}
+		// The team's hints are everyone's to reuse in hard mode
+		previousGuesses = game.Guesses
+	} else {
+		// Check if overlapping attempt number for user
+		for _, guess := range game.Guesses {
+			if guess.PlayerID != player.ID {
+				continue
+			}
+			if guess.AttemptNumber == attemptNumber {
+				return GuessResult{}, invalid("You have already made a guess with this attempt number")
+			}
+			if solves(guess) {
+				return GuessResult{}, invalid("You have already solved the word")
+			}
+			previousGuesses = append(previousGuesses, guess)
+		}
+	}
+
+	// In hard mode every hint this player has revealed must be reused
+	if game.HardMode {
+		if violation := CheckHardMode(guessWord, previousGuesses); violation != "" {
+			return GuessResult{}, invalidCode(violation, "hard_mode_violation")
+		}
+	}
+
+	isCorrect, feedback := Score(guessWord, game.Word)
+	result := GuessResult{
+		Guess: models.Guess{
+			GameID:        game.ID,
+			PlayerID:      player.ID,
+			GuessWord:     guessWord,
+			Feedback:      feedback,
+			AttemptNumber: attemptNumber,
+		},
+	}
+	result.Guess.CreatedAt = now
+
+	guesses := append(game.Guesses, result.Guess)
+	if isCorrect && !everyoneFinishes(game) {
+		result.Outcome = models.RoundOutcomeWon
+		result.Winner = findPlayer(game, player.Username)
+	} else if RoundExhausted(game, guesses, player.Username, online) {
+		// The round is over once every online player has solved the word or
+		// used all their attempts, and won if anyone solved it
+		result.Outcome = models.RoundOutcomeLost
+		if result.Winner = FirstSolver(game, guesses); result.Winner != nil {
+			result.Outcome = models.RoundOutcomeWon
+		}
+	}
+
+	return result, nil
+}
+
+// LeaveResult describes what a player leaving did to the game
+type LeaveResult struct {
+	// The player who is admin now, if anyone is left
+	Admin *models.Player
+	// Nobody is left and the game should be deleted
+	Empty bool
+	// Only one player is left mid-round, so the round is abandoned
+	Abandon bool
+	// Everyone left online mid-round is done guessing, so the round is over
+	Exhausted bool
+	// States the game moved through, when leaving changed it
+	Changes []StateChange
+}
+
+// Leave removes the player from the game and hands the admin role on. A
+// round that was about to start with only one player left is called off
+func Leave(game *models.Game, username string, online func(username string) bool, now time.Time) (LeaveResult, error) {
+	index := -1
+	for i, player := range game.Players {
+		if player.Username == username {
+			index = i
+			break
+		}
+	}
+	if index == -1 {
+		return LeaveResult{}, invalid("You are not in this game")
+	}
+
+	game.Players = append(game.Players[:index], game.Players[index+1:]...)
+
+	result := LeaveResult{}
+	if len(game.Players) == 0 {
+		result.Empty = true
+		if CanTransition(currentState(*game), models.GameStateAbandoned) {
+			change, _ := Transition(game, models.GameStateAbandoned, now)
+			result.Changes = append(result.Changes, change)
+		}
+		return result, nil
+	}
+
+	// Make the next player the admin
+	game.Players[0].IsAdmin = true
+	result.Admin = &game.Players[0]
+
+	if game.State == models.GameStateInProgress && len(game.Players) > 1 {
+		result.Exhausted = RoundExhausted(*game, game.Guesses, "", online)
+	}
+
+	if len(game.Players) == 1 {
+		switch game.State {
+		case models.GameStateInProgress:
+			result.Abandon = true
+		case models.GameStateCountdown:
+			change, err := Transition(game, models.GameStateLobby, now)
+			if err != nil {
+				return result, err
+			}
+			game.Word = ""
+			game.GameMasterID = nil
+			result.Changes = append(result.Changes, change)
+		}
+	}
+	return result, nil
+}
+
+// RoundEnd is a finished round and the states the game moved through
+type RoundEnd struct {
+	Round      models.Round
+	Word       string
+	Changes    []StateChange
+	Scoreboard Scoreboard
+	// Players the round knocked out of a battle royale
+	Eliminated []models.Player
+	// The round was the last of the match
+	MatchOver bool
+	// More rounds of the match are left to play
+	NextRound bool
+}
+
+// End finishes the round and adds its points to the match. A won or lost
+// round shows its results until the next one starts, or finishes the match
+// after its last round. An abandoned one calls off the match and reopens the
+// lobby for whoever is left
+func End(game *models.Game, winner *models.Player, outcome models.RoundOutcome, now time.Time) (RoundEnd, error) {
+	if game.State != models.GameStateInProgress {
+		return RoundEnd{}, invalid("Game is not in progress")
+	}
+
+	end := RoundEnd{
+		Round: BuildRound(*game, winner, outcome, now),
+		Word:  game.Word,
+	}
+
+	if game.Scores == nil {
+		game.Scores = make(map[uint]int)
+	}
+	for _, player := range end.Round.Players {
+		game.Scores[player.PlayerID] += player.Points
+	}
+
+	if IsElimination(*game) && outcome != models.RoundOutcomeAbandoned {
+		for _, id := range eliminate(end.Round) {
+			game.Eliminated = append(game.Eliminated, id)
+			for _, player := range game.Players {
+				if player.ID == id {
+					end.Eliminated = append(end.Eliminated, player)
+				}
+			}
+		}
+	}
+	end.Scoreboard = BuildScoreboard(*game, end.Round)
+
+	path := []models.GameState{models.GameStateRoundOver}
+	switch {
+	case outcome == models.RoundOutcomeAbandoned:
+		path = []models.GameState{models.GameStateAbandoned, models.GameStateLobby}
+		game.RoundNumber = 0
+	case MatchOver(*game):
+		path = append(path, models.GameStateFinished)
+		end.MatchOver = true
+	default:
+		end.NextRound = true
+	}
+	for _, state := range path {
+		change, err := Transition(game, state, now)
+		if err != nil {
+			return RoundEnd{}, err
+		}
+		end.Changes = append(end.Changes, change)
+	}
+
+	game.Word = ""
+	game.GameMasterID = nil
+	game.Deadline = nil
+
+	return end, nil
+}
+
+// BuildRound builds the history record for the round that is ending
+func BuildRound(game models.Game, winner *models.Player, outcome models.RoundOutcome, now time.Time) models.Round {
+	round := models.Round{
+		GameID:       game.ID,
+		Word:         game.Word,
+		WordLength:   game.WordLength,
+		HardMode:     game.HardMode,
+		EndedAt:      now,
+		GameMasterID: game.GameMasterID,
+		Outcome:      outcome,
+		MatchRound:   game.RoundNumber,
+	}
+
+	if game.StartedAt != nil {
+		round.StartedAt = *game.StartedAt
+	} else {
+		round.StartedAt = round.EndedAt
+	}
+
+	if winner != nil {
+		round.WinnerID = &winner.ID
+	}
+
+	attempts := make(map[uint]uint)
+	solvedAt := make(map[uint]time.Time)
+	for _, guess := range game.Guesses {
+		attempts[guess.PlayerID]++
+		if solves(guess) {
+			solvedAt[guess.PlayerID] = guess.CreatedAt
+		}
+		round.Guesses = append(round.Guesses, models.RoundGuess{
+			PlayerID:      guess.PlayerID,
+			GuessWord:     guess.GuessWord,
+			Feedback:      guess.Feedback,
+			AttemptNumber: guess.AttemptNumber,
+		})
+	}
+
+	for _, player := range game.Players {
+		// The game master knew the word, so the round doesn't count for them,
+		// and spectators of a battle royale didn't play it
+		if IsGameMaster(game, player.ID) || IsEliminated(game, player.ID) {
+			continue
+		}
+
+		roundPlayer := models.RoundPlayer{
+			PlayerID:     player.ID,
+			Username:     player.Username,
+			AttemptsUsed: attempts[player.ID],
+		}
+		// Only the winner solves a classic round, everyone can in a race, a
+		// co-op team shares its attempts and its result, and the winner's whole
+		// team wins a team game
+		if IsTeams(game) {
+			if winner != nil && game.Teams[player.ID] == game.Teams[winner.ID] {
+				roundPlayer.Solved = true
+				roundPlayer.SolvedAt = &round.EndedAt
+				roundPlayer.Points = roundPoints(game, attempts[winner.ID], round.EndedAt.Sub(round.StartedAt))
+			}
+		} else if IsCoop(game) {
+			roundPlayer.AttemptsUsed = uint(len(game.Guesses))
+			if winner != nil {
+				roundPlayer.Solved = true
+				roundPlayer.SolvedAt = &round.EndedAt
+				roundPlayer.Points = roundPoints(game, roundPlayer.AttemptsUsed, round.EndedAt.Sub(round.StartedAt))
+			}
+		} else if at, ok := solvedAt[player.ID]; ok {
+			roundPlayer.Solved = true
+			roundPlayer.SolvedAt = &at
+			roundPlayer.Points = roundPoints(game, roundPlayer.AttemptsUsed, at.Sub(round.StartedAt))
+		}
+		round.Players = append(round.Players, roundPlayer)
+	}
+
+	return round
+}
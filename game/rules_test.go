@@ -0,0 +1,629 @@
+package game
+
+import (
+	"errors"
+	"multiplayer-wordle/dictionary"
+	"multiplayer-wordle/models"
+	"os"
+	"testing"
+	"time"
+)
+
+func TestMain(m *testing.M) {
+	dictionary.Load()
+	os.Exit(m.Run())
+}
+
+var testNow = time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+
+// testPlayers makes players with IDs from 1 in the order given, the first is admin
+func testPlayers(usernames ...string) []models.Player {
+	players := make([]models.Player, len(usernames))
+	for i, username := range usernames {
+		players[i].ID = uint(i + 1)
+		players[i].Username = username
+		players[i].IsAdmin = i == 0
+	}
+	return players
+}
+
+// testRound is a round of the mode in progress with the word crane
+func testRound(mode models.GameMode, usernames ...string) models.Game {
+	game := models.Game{
+		State:       models.GameStateInProgress,
+		Word:        "crane",
+		WordLength:  5,
+		Mode:        mode,
+		Rounds:      1,
+		RoundNumber: 1,
+		Players:     testPlayers(usernames...),
+	}
+	game.ID = 1
+	started := testNow.Add(-time.Minute)
+	game.StartedAt = &started
+	game.StateChangedAt = map[models.GameState]time.Time{models.GameStateInProgress: started}
+	return game
+}
+
+// guessOf is a scored guess of the word crane
+func guessOf(playerID, attempt uint, word string) models.Guess {
+	_, feedback := Score(word, "crane")
+	guess := models.Guess{GameID: 1, PlayerID: playerID, GuessWord: word, Feedback: feedback, AttemptNumber: attempt}
+	guess.CreatedAt = testNow.Add(-time.Duration(6-attempt) * time.Second)
+	return guess
+}
+
+// missed fills up every attempt of a player with wrong guesses
+func missed(playerID uint) []models.Guess {
+	var guesses []models.Guess
+	for attempt := uint(0); attempt < 6; attempt++ {
+		guesses = append(guesses, guessOf(playerID, attempt, "slate"))
+	}
+	return guesses
+}
+
+// onlineExcept has everyone online apart from the players given
+func onlineExcept(offline ...string) func(string) bool {
+	return func(username string) bool {
+		for _, name := range offline {
+			if name == username {
+				return false
+			}
+		}
+		return true
+	}
+}
+
+// errorCode returns the code of a game error, or the message when it has none
+func errorCode(err error) string {
+	var gameErr *Error
+	if !errors.As(err, &gameErr) {
+		return ""
+	}
+	if gameErr.Code != "" {
+		return gameErr.Code
+	}
+	return gameErr.Message
+}
+
+func TestScore(t *testing.T) {
+	tests := []struct {
+		guess, word string
+		correct     bool
+		feedback    string
+	}{
+		{"crane", "crane", true, "22222"},
+		{"trace", "crane", false, "02212"},
+		{"slate", "crane", false, "00202"},
+		// Only as many yellows as the word has of a letter
+		{"apple", "plant", false, "11010"},
+		// A green uses the letter up before yellows are handed out
+		{"speed", "abbey", false, "00020"},
+	}
+
+	for _, tt := range tests {
+		correct, feedback := Score(tt.guess, tt.word)
+		if correct != tt.correct || feedback != tt.feedback {
+			t.Errorf("Score(%q, %q) = %v, %q, want %v, %q", tt.guess, tt.word, correct, feedback, tt.correct, tt.feedback)
+		}
+	}
+}
+
+func TestCheckHardMode(t *testing.T) {
+	// trace against crane: R, A and E are green, C is yellow
+	previous := []models.Guess{{GuessWord: "trace", Feedback: "02212"}}
+
+	tests := []struct {
+		guess string
+		want  string
+	}{
+		{"crane", ""},
+		{"grace", ""},
+		{"slate", "2nd letter must be R"},
+		{"brave", "Guess must contain C"},
+	}
+
+	for _, tt := range tests {
+		if got := CheckHardMode(tt.guess, previous); got != tt.want {
+			t.Errorf("CheckHardMode(%q) = %q, want %q", tt.guess, got, tt.want)
+		}
+	}
+
+	if got := CheckHardMode("slate", nil); got != "" {
+		t.Errorf("CheckHardMode without previous guesses = %q, want no violation", got)
+	}
+}
+
+func TestGuess(t *testing.T) {
+	tests := []struct {
+		name    string
+		game    func() models.Game
+		player  int // Index into the game's players
+		word    string
+		attempt uint
+		offline []string // Players who don't have the game open
+		err     string   // Code or message of the expected error
+		outcome models.RoundOutcome
+		winner  string
+	}{
+		{
+			name:    "wrong guess keeps the round going",
+			game:    func() models.Game { return testRound(models.GameModeClassic, "alice", "bob") },
+			word:    "slate",
+			outcome: "",
+		},
+		{
+			name:    "first solve wins a classic round",
+			game:    func() models.Game { return testRound(models.GameModeClassic, "alice", "bob") },
+			player:  1,
+			word:    "CRANE",
+			outcome: models.RoundOutcomeWon,
+			winner:  "bob",
+		},
+		{
+			name:    "a race goes on after a solve",
+			game:    func() models.Game { return testRound(models.GameModeRace, "alice", "bob") },
+			word:    "crane",
+			outcome: "",
+		},
+		{
+			name: "a race is won once everyone is done",
+			game: func() models.Game {
+				game := testRound(models.GameModeRace, "alice", "bob")
+				game.Guesses = missed(2)
+				return game
+			},
+			word:    "crane",
+			outcome: models.RoundOutcomeWon,
+			winner:  "alice",
+		},
+		{
+			name:    "a race is won once everyone online is done",
+			game:    func() models.Game { return testRound(models.GameModeRace, "alice", "bob") },
+			word:    "crane",
+			offline: []string{"bob"},
+			outcome: models.RoundOutcomeWon,
+			winner:  "alice",
+		},
+		{
+			name: "the guesser counts without having the game open",
+			game: func() models.Game {
+				game := testRound(models.GameModeClassic, "alice", "bob")
+				game.Guesses = missed(1)[:5]
+				return game
+			},
+			word:    "slate",
+			attempt: 5,
+			offline: []string{"alice", "bob"},
+			outcome: models.RoundOutcomeLost,
+		},
+		{
+			name: "the last attempt of the last player loses the round",
+			game: func() models.Game {
+				game := testRound(models.GameModeClassic, "alice", "bob")
+				game.Guesses = append(missed(1)[:5], missed(2)...)
+				return game
+			},
+			word:    "slate",
+			attempt: 5,
+			outcome: models.RoundOutcomeLost,
+		},
+		{
+			name: "a player still guessing holds the round open",
+			game: func() models.Game {
+				game := testRound(models.GameModeClassic, "alice", "bob")
+				game.Guesses = missed(1)[:5]
+				return game
+			},
+			word:    "slate",
+			attempt: 5,
+			outcome: "",
+		},
+		{
+			name: "the game master can't guess",
+			game: func() models.Game {
+				game := testRound(models.GameModeClassic, "alice", "bob")
+				game.GameMasterID = &game.Players[0].ID
+				return game
+			},
+			word: "crane",
+			err:  "The game master cannot guess",
+		},
+		{
+			name: "an eliminated player can't guess",
+			game: func() models.Game {
+				game := testRound(models.GameModeElimination, "alice", "bob", "carol")
+				game.Eliminated = []uint{1}
+				return game
+			},
+			word: "crane",
+			err:  "eliminated",
+		},
+		{
+			name: "an attempt can only be used once",
+			game: func() models.Game {
+				game := testRound(models.GameModeClassic, "alice", "bob")
+				game.Guesses = missed(1)[:1]
+				return game
+			},
+			word: "grace",
+			err:  "You have already made a guess with this attempt number",
+		},
+		{
+			name: "nothing to guess after solving",
+			game: func() models.Game {
+				game := testRound(models.GameModeRace, "alice", "bob")
+				game.Guesses = []models.Guess{guessOf(1, 0, "crane")}
+				return game
+			},
+			word:    "grace",
+			attempt: 1,
+			err:     "You have already solved the word",
+		},
+		{
+			name: "hard mode keeps the hints",
+			game: func() models.Game {
+				game := testRound(models.GameModeClassic, "alice", "bob")
+				game.HardMode = true
+				game.Guesses = []models.Guess{guessOf(1, 0, "trace")}
+				return game
+			},
+			word:    "brave",
+			attempt: 1,
+			err:     "hard_mode_violation",
+		},
+		{
+			name: "a late race guess doesn't count",
+			game: func() models.Game {
+				game := testRound(models.GameModeRace, "alice", "bob")
+				deadline := testNow.Add(-time.Second)
+				game.Deadline = &deadline
+				return game
+			},
+			word: "crane",
+			err:  "time_up",
+		},
+		{
+			name: "co-op guesses fill the next row",
+			game: func() models.Game {
+				game := testRound(models.GameModeCoop, "alice", "bob")
+				game.Guesses = []models.Guess{guessOf(2, 0, "slate")}
+				return game
+			},
+			word:    "grace",
+			attempt: 2,
+			err:     "wrong_attempt",
+		},
+		{
+			name: "co-op ends when the shared attempts run out",
+			game: func() models.Game {
+				game := testRound(models.GameModeCoop, "alice", "bob")
+				game.SharedAttempts = 2
+				game.Guesses = []models.Guess{guessOf(2, 0, "slate")}
+				return game
+			},
+			word:    "grace",
+			attempt: 1,
+			outcome: models.RoundOutcomeLost,
+		},
+		{
+			name: "no guessing outside a round",
+			game: func() models.Game {
+				game := testRound(models.GameModeClassic, "alice", "bob")
+				game.State = models.GameStateRoundOver
+				return game
+			},
+			word: "crane",
+			err:  "Game is not in progress",
+		},
+		{
+			name: "only dictionary words",
+			game: func() models.Game { return testRound(models.GameModeClassic, "alice", "bob") },
+			word: "xyzzq",
+			err:  "not_a_word",
+		},
+		{
+			name: "only letters",
+			game: func() models.Game { return testRound(models.GameModeClassic, "alice", "bob") },
+			word: "cr4ne",
+			err:  "invalid_characters",
+		},
+		{
+			name: "the word length must match",
+			game: func() models.Game { return testRound(models.GameModeClassic, "alice", "bob") },
+			word: "cranes",
+			err:  "The guess word must be exactly 5 letters",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			game := tt.game()
+			player := game.Players[tt.player]
+
+			result, err := Guess(game, player, tt.word, tt.attempt, onlineExcept(tt.offline...), testNow)
+			if tt.err != "" {
+				if got := errorCode(err); got != tt.err {
+					t.Fatalf("Guess() error = %v (%q), want %q", err, got, tt.err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Guess() error = %v", err)
+			}
+
+			if result.Outcome != tt.outcome {
+				t.Errorf("outcome = %q, want %q", result.Outcome, tt.outcome)
+			}
+			winner := ""
+			if result.Winner != nil {
+				winner = result.Winner.Username
+			}
+			if winner != tt.winner {
+				t.Errorf("winner = %q, want %q", winner, tt.winner)
+			}
+			if result.Guess.PlayerID != player.ID || result.Guess.AttemptNumber != tt.attempt || !result.Guess.CreatedAt.Equal(testNow) {
+				t.Errorf("guess = %+v", result.Guess)
+			}
+		})
+	}
+}
+
+func TestLeave(t *testing.T) {
+	tests := []struct {
+		name      string
+		game      func() models.Game
+		username  string
+		offline   []string
+		err       string
+		empty     bool
+		abandon   bool
+		exhausted bool
+		admin     string
+		state     models.GameState
+	}{
+		{
+			name: "the last player leaving empties the game",
+			game: func() models.Game {
+				game := testRound(models.GameModeClassic, "alice")
+				game.State = models.GameStateLobby
+				return game
+			},
+			username: "alice",
+			empty:    true,
+			state:    models.GameStateAbandoned,
+		},
+		{
+			name: "the admin role is handed on",
+			game: func() models.Game {
+				game := testRound(models.GameModeClassic, "alice", "bob")
+				game.State = models.GameStateLobby
+				return game
+			},
+			username: "alice",
+			admin:    "bob",
+			state:    models.GameStateLobby,
+		},
+		{
+			name:     "a round with one player left is abandoned",
+			game:     func() models.Game { return testRound(models.GameModeClassic, "alice", "bob") },
+			username: "bob",
+			abandon:  true,
+			admin:    "alice",
+			state:    models.GameStateInProgress,
+		},
+		{
+			name: "a countdown with one player left is called off",
+			game: func() models.Game {
+				game := testRound(models.GameModeClassic, "alice", "bob")
+				game.State = models.GameStateCountdown
+				return game
+			},
+			username: "bob",
+			admin:    "alice",
+			state:    models.GameStateLobby,
+		},
+		{
+			name: "the round ends when everyone left is done",
+			game: func() models.Game {
+				game := testRound(models.GameModeClassic, "alice", "bob", "carol")
+				game.Guesses = append(missed(1), missed(2)...)
+				return game
+			},
+			username:  "carol",
+			exhausted: true,
+			admin:     "alice",
+			state:     models.GameStateInProgress,
+		},
+		{
+			name: "the round goes on while others still guess",
+			game: func() models.Game {
+				game := testRound(models.GameModeClassic, "alice", "bob", "carol")
+				game.Guesses = missed(1)
+				return game
+			},
+			username: "carol",
+			admin:    "alice",
+			state:    models.GameStateInProgress,
+		},
+		{
+			name: "players who dropped off don't keep the round going",
+			game: func() models.Game {
+				game := testRound(models.GameModeClassic, "alice", "bob", "carol")
+				game.Guesses = missed(1)
+				return game
+			},
+			username:  "carol",
+			offline:   []string{"bob"},
+			exhausted: true,
+			admin:     "alice",
+			state:     models.GameStateInProgress,
+		},
+		{
+			name:     "only players in the game can leave it",
+			game:     func() models.Game { return testRound(models.GameModeClassic, "alice", "bob") },
+			username: "dave",
+			err:      "You are not in this game",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			game := tt.game()
+			result, err := Leave(&game, tt.username, onlineExcept(tt.offline...), testNow)
+			if tt.err != "" {
+				if got := errorCode(err); got != tt.err {
+					t.Fatalf("Leave() error = %v, want %q", err, tt.err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Leave() error = %v", err)
+			}
+
+			if result.Empty != tt.empty || result.Abandon != tt.abandon || result.Exhausted != tt.exhausted {
+				t.Errorf("result = %+v", result)
+			}
+			if findPlayer(game, tt.username) != nil {
+				t.Errorf("%s is still in the game", tt.username)
+			}
+			admin := ""
+			if result.Admin != nil {
+				admin = result.Admin.Username
+				if !game.Players[0].IsAdmin {
+					t.Errorf("%s was not made admin in the game", admin)
+				}
+			}
+			if admin != tt.admin {
+				t.Errorf("admin = %q, want %q", admin, tt.admin)
+			}
+			if game.State != tt.state {
+				t.Errorf("state = %q, want %q", game.State, tt.state)
+			}
+		})
+	}
+}
+
+func TestEnd(t *testing.T) {
+	tests := []struct {
+		name       string
+		game       func() models.Game
+		winner     int // Index of the winner in the game's players, -1 for none
+		outcome    models.RoundOutcome
+		path       []models.GameState
+		matchOver  bool
+		nextRound  bool
+		eliminated []uint
+		points     map[uint]bool // Whether each player scored
+	}{
+		{
+			name:      "a single round won finishes the match",
+			game:      func() models.Game { return testRound(models.GameModeClassic, "alice", "bob") },
+			winner:    0,
+			outcome:   models.RoundOutcomeWon,
+			path:      []models.GameState{models.GameStateRoundOver, models.GameStateFinished},
+			matchOver: true,
+			points:    map[uint]bool{1: true, 2: false},
+		},
+		{
+			name: "a match goes on after its first round",
+			game: func() models.Game {
+				game := testRound(models.GameModeClassic, "alice", "bob")
+				game.Rounds = 3
+				return game
+			},
+			winner:    1,
+			outcome:   models.RoundOutcomeWon,
+			path:      []models.GameState{models.GameStateRoundOver},
+			nextRound: true,
+			points:    map[uint]bool{1: false, 2: true},
+		},
+		{
+			name: "an abandoned round reopens the lobby",
+			game: func() models.Game {
+				game := testRound(models.GameModeClassic, "alice", "bob")
+				game.Rounds = 3
+				return game
+			},
+			winner:  -1,
+			outcome: models.RoundOutcomeAbandoned,
+			path:    []models.GameState{models.GameStateAbandoned, models.GameStateLobby},
+			points:  map[uint]bool{1: false, 2: false},
+		},
+		{
+			name: "a battle royale knocks out everyone who didn't solve",
+			game: func() models.Game {
+				game := testRound(models.GameModeElimination, "alice", "bob", "carol")
+				game.Guesses = append([]models.Guess{guessOf(1, 0, "crane")}, append(missed(2), missed(3)...)...)
+				return game
+			},
+			winner:     0,
+			outcome:    models.RoundOutcomeWon,
+			path:       []models.GameState{models.GameStateRoundOver, models.GameStateFinished},
+			matchOver:  true,
+			eliminated: []uint{2, 3},
+			points:     map[uint]bool{1: true, 2: false, 3: false},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			game := tt.game()
+			var winner *models.Player
+			if tt.winner >= 0 {
+				winner = &game.Players[tt.winner]
+				if len(game.Guesses) == 0 {
+					game.Guesses = []models.Guess{guessOf(winner.ID, 0, "crane")}
+				}
+			}
+
+			end, err := End(&game, winner, tt.outcome, testNow)
+			if err != nil {
+				t.Fatalf("End() error = %v", err)
+			}
+
+			if len(end.Changes) != len(tt.path) {
+				t.Fatalf("changes = %+v, want the path %v", end.Changes, tt.path)
+			}
+			for i, state := range tt.path {
+				if end.Changes[i].To != state {
+					t.Errorf("change %d went to %q, want %q", i, end.Changes[i].To, state)
+				}
+			}
+			if game.State != tt.path[len(tt.path)-1] {
+				t.Errorf("state = %q, want %q", game.State, tt.path[len(tt.path)-1])
+			}
+			if end.MatchOver != tt.matchOver || end.NextRound != tt.nextRound {
+				t.Errorf("MatchOver = %v, NextRound = %v", end.MatchOver, end.NextRound)
+			}
+
+			if end.Word != "crane" || game.Word != "" || game.GameMasterID != nil || game.Deadline != nil {
+				t.Errorf("the round was not cleared, word %q, game %+v", end.Word, game)
+			}
+			if end.Round.Outcome != tt.outcome {
+				t.Errorf("round outcome = %q, want %q", end.Round.Outcome, tt.outcome)
+			}
+
+			var eliminated []uint
+			for _, player := range end.Eliminated {
+				eliminated = append(eliminated, player.ID)
+			}
+			if len(eliminated) != len(tt.eliminated) {
+				t.Errorf("eliminated = %v, want %v", eliminated, tt.eliminated)
+			}
+			for playerID, scored := range tt.points {
+				if (game.Scores[playerID] > 0) != scored {
+					t.Errorf("player %d has %d points", playerID, game.Scores[playerID])
+				}
+			}
+		})
+	}
+
+	t.Run("only a running round can end", func(t *testing.T) {
+		game := testRound(models.GameModeClassic, "alice", "bob")
+		game.State = models.GameStateLobby
+		if _, err := End(&game, nil, models.RoundOutcomeLost, testNow); errorCode(err) != "Game is not in progress" {
+			t.Errorf("End() error = %v", err)
+		}
+	})
+}
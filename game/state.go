@@ -0,0 +1,75 @@
+package game
+
+import (
+	"fmt"
+	"multiplayer-wordle/models"
+	"time"
+)
+
+// transitions lists the states each state may move to. Everything else is
+// rejected by Transition
+var transitions = map[models.GameState][]models.GameState{
+	models.GameStateLobby:      {models.GameStateCountdown, models.GameStateAbandoned},
+	models.GameStateCountdown:  {models.GameStateInProgress, models.GameStateLobby, models.GameStateAbandoned},
+	models.GameStateInProgress: {models.GameStateRoundOver, models.GameStateAbandoned},
+	models.GameStateRoundOver:  {models.GameStateCountdown, models.GameStateLobby, models.GameStateFinished, models.GameStateAbandoned},
+	models.GameStateFinished:   {models.GameStateLobby, models.GameStateCountdown, models.GameStateAbandoned},
+	// A game abandoned mid-round reopens its lobby for whoever is left
+	models.GameStateAbandoned: {models.GameStateLobby},
+}
+
+// StateChange is one move of the state machine
+type StateChange struct {
+	From models.GameState
+	To   models.GameState
+	At   time.Time
+}
+
+// CanTransition reports whether the state machine allows the move
+func CanTransition(from, to models.GameState) bool {
+	for _, allowed := range transitions[from] {
+		if allowed == to {
+			return true
+		}
+	}
+	return false
+}
+
+// Joinable reports whether players may join the game in its current state,
+// they can't drop into a round that is starting or running
+func Joinable(game models.Game) bool {
+	switch currentState(game) {
+	case models.GameStateLobby, models.GameStateRoundOver, models.GameStateFinished:
+		return true
+	}
+	return false
+}
+
+// Transition moves the game to another state and records when it happened,
+// or rejects a move the state machine doesn't allow
+func Transition(game *models.Game, to models.GameState, now time.Time) (StateChange, error) {
+	from := currentState(*game)
+	if !CanTransition(from, to) {
+		return StateChange{}, &Error{
+			Kind:    KindInvalid,
+			Message: fmt.Sprintf("Game cannot go from %s to %s", from, to),
+			Code:    "illegal_transition",
+		}
+	}
+
+	game.State = to
+	if game.StateChangedAt == nil {
+		game.StateChangedAt = make(map[models.GameState]time.Time)
+	}
+	game.StateChangedAt[to] = now
+
+	return StateChange{From: from, To: to, At: now}, nil
+}
+
+// Games created before the state machine have no state recorded
+func currentState(game models.Game) models.GameState {
+	if game.State == "" {
+		return models.GameStateLobby
+	}
+	return game.State
+}
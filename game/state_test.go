@@ -0,0 +1,87 @@
+package game
+
+import (
+	"errors"
+	"multiplayer-wordle/models"
+	"testing"
+	"time"
+)
+
+func TestTransition(t *testing.T) {
+	now := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name string
+		from models.GameState
+		to   models.GameState
+		ok   bool
+	}{
+		{"lobby to countdown", models.GameStateLobby, models.GameStateCountdown, true},
+		{"no state counts as lobby", "", models.GameStateCountdown, true},
+		{"countdown to in progress", models.GameStateCountdown, models.GameStateInProgress, true},
+		{"countdown called off", models.GameStateCountdown, models.GameStateLobby, true},
+		{"in progress to round over", models.GameStateInProgress, models.GameStateRoundOver, true},
+		{"in progress abandoned", models.GameStateInProgress, models.GameStateAbandoned, true},
+		{"next round of a match", models.GameStateRoundOver, models.GameStateCountdown, true},
+		{"match finished", models.GameStateRoundOver, models.GameStateFinished, true},
+		{"new match after finishing", models.GameStateFinished, models.GameStateCountdown, true},
+		{"abandoned reopens the lobby", models.GameStateAbandoned, models.GameStateLobby, true},
+		{"lobby can't skip the countdown", models.GameStateLobby, models.GameStateInProgress, false},
+		{"in progress can't go back to lobby", models.GameStateInProgress, models.GameStateLobby, false},
+		{"countdown can't end a round", models.GameStateCountdown, models.GameStateRoundOver, false},
+		{"abandoned can't start a round", models.GameStateAbandoned, models.GameStateCountdown, false},
+		{"no move to the same state", models.GameStateInProgress, models.GameStateInProgress, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			game := models.Game{State: tt.from}
+			change, err := Transition(&game, tt.to, now)
+
+			if !tt.ok {
+				var gameErr *Error
+				if !errors.As(err, &gameErr) || gameErr.Code != "illegal_transition" {
+					t.Fatalf("Transition() error = %v, want illegal_transition", err)
+				}
+				if game.State != tt.from {
+					t.Errorf("state = %q after a rejected move, want %q", game.State, tt.from)
+				}
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("Transition() error = %v", err)
+			}
+			if game.State != tt.to {
+				t.Errorf("state = %q, want %q", game.State, tt.to)
+			}
+			if !game.StateChangedAt[tt.to].Equal(now) {
+				t.Errorf("StateChangedAt[%q] = %v, want %v", tt.to, game.StateChangedAt[tt.to], now)
+			}
+			if change.From != currentState(models.Game{State: tt.from}) || change.To != tt.to || !change.At.Equal(now) {
+				t.Errorf("change = %+v", change)
+			}
+		})
+	}
+}
+
+func TestJoinable(t *testing.T) {
+	tests := []struct {
+		state models.GameState
+		want  bool
+	}{
+		{"", true},
+		{models.GameStateLobby, true},
+		{models.GameStateCountdown, false},
+		{models.GameStateInProgress, false},
+		{models.GameStateRoundOver, true},
+		{models.GameStateFinished, true},
+		{models.GameStateAbandoned, false},
+	}
+
+	for _, tt := range tests {
+		if got := Joinable(models.Game{State: tt.state}); got != tt.want {
+			t.Errorf("Joinable(%q) = %v, want %v", tt.state, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,47 @@
+package game
+
+import (
+	"errors"
+	"multiplayer-wordle/models"
+)
+
+// ErrNotFound is returned by a Store when a game or player doesn't exist
+var ErrNotFound = errors.New("not found")
+
+// ErrDuplicate is returned by CreateGuess when the player already made a
+// guess with that attempt number
+var ErrDuplicate = errors.New("duplicate")
+
+// Store is how the engine loads and saves games, so the rules can run
+// against any backend
+type Store interface {
+	// Transaction runs fn with a Store whose changes are committed together.
+	// Games and players read through it stay locked until fn returns
+	Transaction(fn func(store Store) error) error
+	Player(username string) (models.Player, error)
+	// Game loads a game with its players, their teams and current guesses
+	Game(id uint) (models.Game, error)
+	// TimedGames loads the games waiting on a timer: counting down, racing the
+	// clock or between the rounds of a match
+	TimedGames() ([]models.Game, error)
+	CreateGame(game *models.Game, creator *models.Player) error
+	// AddPlayer puts the player in the game. It leaves game.Players alone,
+	// the caller adds them once the join went through
+	AddPlayer(game *models.Game, player *models.Player) error
+	// SetTeams records the team of each player given in a team game
+	SetTeams(game models.Game, teams map[uint]int) error
+	// RemovePlayer takes the player out of the game and saves the new admin,
+	// if any. Like AddPlayer it leaves game.Players to the caller
+	RemovePlayer(game *models.Game, player *models.Player, admin *models.Player) error
+	DeleteGame(game models.Game) error
+	// SaveGame saves the game itself, not its players or guesses
+	SaveGame(game *models.Game) error
+	CreateGuess(guess *models.Guess) error
+	// PickWord chooses the word for a round that has no custom word
+	PickWord(game models.Game) (string, error)
+	// Scheduled reports whether PickWord will play a word the admin queued
+	Scheduled(game models.Game) (bool, error)
+	// FinishRound records the round, clears its guesses, saves the game and
+	// updates the stats and ratings of everyone who played, all or nothing
+	FinishRound(game models.Game, round models.Round) error
+}
@@ -0,0 +1,210 @@
+package game
+
+import (
+	"multiplayer-wordle/models"
+	"time"
+)
+
+// memStore is an in-memory Store for tests. Transactions run straight
+// through and are not rolled back when they fail
+type memStore struct {
+	players map[string]*models.Player
+	games   map[uint]*models.Game
+	// Usernames in each game in the order they joined
+	members map[uint][]string
+	teams   map[uint]map[uint]int
+	// Words the admin queued for each game
+	schedule map[uint][]string
+	rounds   []models.Round
+	nextID   uint
+}
+
+func newMemStore(usernames ...string) *memStore {
+	s := &memStore{
+		players:  make(map[string]*models.Player),
+		games:    make(map[uint]*models.Game),
+		members:  make(map[uint][]string),
+		teams:    make(map[uint]map[uint]int),
+		schedule: make(map[uint][]string),
+	}
+	for _, username := range usernames {
+		s.nextID++
+		player := &models.Player{Username: username}
+		player.ID = s.nextID
+		s.players[username] = player
+	}
+	return s
+}
+
+func (s *memStore) Transaction(fn func(store Store) error) error {
+	return fn(s)
+}
+
+func (s *memStore) Player(username string) (models.Player, error) {
+	player, ok := s.players[username]
+	if !ok {
+		return models.Player{}, ErrNotFound
+	}
+	return *player, nil
+}
+
+func (s *memStore) Game(id uint) (models.Game, error) {
+	stored, ok := s.games[id]
+	if !ok {
+		return models.Game{}, ErrNotFound
+	}
+
+	game := cloneGame(*stored)
+	for _, username := range s.members[id] {
+		game.Players = append(game.Players, *s.players[username])
+	}
+	game.Teams = make(map[uint]int)
+	for playerID, team := range s.teams[id] {
+		game.Teams[playerID] = team
+	}
+	return game, nil
+}
+
+func (s *memStore) TimedGames() ([]models.Game, error) {
+	var games []models.Game
+	for id, stored := range s.games {
+		switch stored.State {
+		case models.GameStateCountdown, models.GameStateInProgress, models.GameStateRoundOver:
+			game, _ := s.Game(id)
+			games = append(games, game)
+		}
+	}
+	return games, nil
+}
+
+func (s *memStore) CreateGame(game *models.Game, creator *models.Player) error {
+	s.nextID++
+	game.ID = s.nextID
+
+	creator.GameID = game.ID
+	creator.IsAdmin = true
+	player := *creator
+	s.players[creator.Username] = &player
+	s.members[game.ID] = []string{creator.Username}
+
+	stored := cloneGame(*game)
+	stored.Players = nil
+	s.games[game.ID] = &stored
+	return nil
+}
+
+// AddPlayer leaves game.Players to the engine, as the Store contract says
+func (s *memStore) AddPlayer(game *models.Game, player *models.Player) error {
+	player.GameID = game.ID
+	player.IsAdmin = false
+	stored := *player
+	s.players[player.Username] = &stored
+	s.members[game.ID] = append(s.members[game.ID], player.Username)
+	return nil
+}
+
+func (s *memStore) SetTeams(game models.Game, teams map[uint]int) error {
+	if s.teams[game.ID] == nil {
+		s.teams[game.ID] = make(map[uint]int)
+	}
+	for playerID, team := range teams {
+		s.teams[game.ID][playerID] = team
+	}
+	return nil
+}
+
+func (s *memStore) RemovePlayer(game *models.Game, player *models.Player, admin *models.Player) error {
+	player.GameID = 0
+	player.IsAdmin = false
+	stored := *player
+	s.players[player.Username] = &stored
+
+	var members []string
+	for _, username := range s.members[game.ID] {
+		if username != player.Username {
+			members = append(members, username)
+		}
+	}
+	s.members[game.ID] = members
+	delete(s.teams[game.ID], player.ID)
+
+	if admin != nil {
+		s.players[admin.Username].IsAdmin = true
+	}
+	return nil
+}
+
+func (s *memStore) DeleteGame(game models.Game) error {
+	for _, player := range s.players {
+		if player.GameID == game.ID {
+			player.GameID = 0
+		}
+	}
+	delete(s.games, game.ID)
+	delete(s.members, game.ID)
+	return nil
+}
+
+func (s *memStore) SaveGame(game *models.Game) error {
+	stored := cloneGame(*game)
+	stored.Players = nil
+	if previous, ok := s.games[game.ID]; ok {
+		stored.Guesses = previous.Guesses
+	}
+	s.games[game.ID] = &stored
+	return nil
+}
+
+func (s *memStore) CreateGuess(guess *models.Guess) error {
+	stored := s.games[guess.GameID]
+	for _, existing := range stored.Guesses {
+		if existing.PlayerID == guess.PlayerID && existing.AttemptNumber == guess.AttemptNumber {
+			return ErrDuplicate
+		}
+	}
+	s.nextID++
+	guess.ID = s.nextID
+	stored.Guesses = append(stored.Guesses, *guess)
+	return nil
+}
+
+func (s *memStore) PickWord(game models.Game) (string, error) {
+	if queued := s.schedule[game.ID]; len(queued) > 0 {
+		s.schedule[game.ID] = queued[1:]
+		return queued[0], nil
+	}
+	return "crane", nil
+}
+
+func (s *memStore) Scheduled(game models.Game) (bool, error) {
+	return len(s.schedule[game.ID]) > 0, nil
+}
+
+func (s *memStore) FinishRound(game models.Game, round models.Round) error {
+	s.rounds = append(s.rounds, round)
+	if err := s.SaveGame(&game); err != nil {
+		return err
+	}
+	s.games[game.ID].Guesses = nil
+	return nil
+}
+
+// cloneGame copies a game so changes to the copy don't reach the stored one
+func cloneGame(game models.Game) models.Game {
+	game.Players = append([]models.Player(nil), game.Players...)
+	game.Guesses = append([]models.Guess(nil), game.Guesses...)
+	game.Eliminated = append([]uint(nil), game.Eliminated...)
+
+	changedAt := make(map[models.GameState]time.Time, len(game.StateChangedAt))
+	for state, at := range game.StateChangedAt {
+		changedAt[state] = at
+	}
+	game.StateChangedAt = changedAt
+
+	scores := make(map[uint]int, len(game.Scores))
+	for playerID, points := range game.Scores {
+		scores[playerID] = points
+	}
+	game.Scores = scores
+	return game
+}
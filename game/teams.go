@@ -0,0 +1,133 @@
+package game
+
+import (
+	"fmt"
+	"multiplayer-wordle/models"
+)
+
+// TeamCount is how many teams a team game is split into, numbered from 1
+const TeamCount = 2
+
+// In a team game every player guesses on their own board, but teammates see
+// each other's guesses while the other team only sees the feedback. The
+// first team to solve the word wins the round
+
+// IsTeams reports whether the game is played team against team
+func IsTeams(game models.Game) bool {
+	return game.Mode == models.GameModeTeams
+}
+
+// teamSizes counts the players on each team
+func teamSizes(game models.Game) map[int]int {
+	sizes := make(map[int]int)
+	for _, player := range game.Players {
+		if team := game.Teams[player.ID]; team != 0 {
+			sizes[team]++
+		}
+	}
+	return sizes
+}
+
+// AutoTeam picks the team for a player joining, the smallest one
+func AutoTeam(game models.Game) int {
+	sizes := teamSizes(game)
+	best := 1
+	for team := 2; team <= TeamCount; team++ {
+		if sizes[team] < sizes[best] {
+			best = team
+		}
+	}
+	return best
+}
+
+// CanSeeGuesses reports whether the viewer may read the words a player
+// guessed: their own, the whole board in co-op, and their teammates' in a
+// team game
+func CanSeeGuesses(game models.Game, viewerID, playerID uint) bool {
+	if viewerID == 0 {
+		return false
+	}
+	if viewerID == playerID || IsCoop(game) || IsGameMaster(game, viewerID) {
+		return true
+	}
+	return IsTeams(game) && game.Teams[viewerID] != 0 && game.Teams[viewerID] == game.Teams[playerID]
+}
+
+// Teammates returns the players on the team, for sharing guesses with them
+func Teammates(game models.Game, team int) []models.Player {
+	var players []models.Player
+	for _, player := range game.Players {
+		if team != 0 && game.Teams[player.ID] == team {
+			players = append(players, player)
+		}
+	}
+	return players
+}
+
+// AssignTeams checks that the admin may split the players up and returns
+// each player's new team. With no assignments the teams are balanced
+// automatically, in the order the players joined
+func AssignTeams(game models.Game, username string, assignments map[string]int) (map[uint]int, error) {
+	player := findPlayer(game, username)
+	if player == nil {
+		return nil, invalid("You are not in this game")
+	}
+
+	if !player.IsAdmin {
+		return nil, invalid("You are not the admin")
+	}
+
+	if !IsTeams(game) {
+		return nil, invalid("This game is not played in teams")
+	}
+
+	if !Joinable(game) || matchUnderway(game) {
+		return nil, invalid("Teams can only change between matches")
+	}
+
+	teams := make(map[uint]int, len(game.Players))
+	if len(assignments) == 0 {
+		for i, player := range game.Players {
+			teams[player.ID] = i%TeamCount + 1
+		}
+		return teams, nil
+	}
+
+	for _, player := range game.Players {
+		teams[player.ID] = game.Teams[player.ID]
+	}
+	for name, team := range assignments {
+		player := findPlayer(game, name)
+		if player == nil {
+			return nil, invalid(fmt.Sprintf("%s is not in this game", name))
+		}
+		if team < 1 || team > TeamCount {
+			return nil, invalid(fmt.Sprintf("Team must be between 1 and %d", TeamCount))
+		}
+		teams[player.ID] = team
+	}
+	return teams, nil
+}
+
+// checkTeams makes sure every player but the game master is on a team and no
+// team is empty before a round starts
+func checkTeams(game models.Game, gameMasterID uint) error {
+	sizes := make(map[int]int)
+	for _, player := range game.Players {
+		if player.ID == gameMasterID {
+			continue
+		}
+		team := game.Teams[player.ID]
+		if team == 0 {
+			return invalid(fmt.Sprintf("%s is not on a team", player.Username))
+		}
+		sizes[team]++
+	}
+
+	for team := 1; team <= TeamCount; team++ {
+		if sizes[team] == 0 {
+			return invalid("Every team needs at least one player")
+		}
+	}
+	return nil
+}
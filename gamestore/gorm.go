@@ -0,0 +1,219 @@
+package gamestore
+
+import (
+	"errors"
+	"multiplayer-wordle/game"
+	"multiplayer-wordle/models"
+	"multiplayer-wordle/rating"
+	"multiplayer-wordle/stats"
+	"multiplayer-wordle/wordselect"
+
+	"github.com/jackc/pgx/v5/pgconn"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// Gorm is the Postgres backed game.Store used by the server
+type Gorm struct {
+	DB *gorm.DB
+	// Locking takes a row lock on every game and player read, set inside a
+	// transaction
+	Locking bool
+}
+
+func (s Gorm) Transaction(fn func(store game.Store) error) error {
+	return s.DB.Transaction(func(tx *gorm.DB) error {
+		return fn(Gorm{DB: tx, Locking: true})
+	})
+}
+
+// forUpdate locks the rows the query reads until the transaction ends
+func (s Gorm) forUpdate(db *gorm.DB) *gorm.DB {
+	if !s.Locking {
+		return db
+	}
+	return db.Clauses(clause.Locking{Strength: "UPDATE"})
+}
+
+func (s Gorm) Player(username string) (models.Player, error) {
+	var player models.Player
+	err := s.forUpdate(s.DB).Where("username = ?", username).First(&player).Error
+	return player, notFoundOr(err)
+}
+
+func (s Gorm) Game(id uint) (models.Game, error) {
+	var current models.Game
+
+	// Lock the game row on its own, Postgres won't lock a query with preloads
+	if s.Locking {
+		if err := s.forUpdate(s.DB).Select("id").Where("id = ?", id).First(&models.Game{}).Error; err != nil {
+			return current, notFoundOr(err)
+		}
+	}
+
+	err := s.DB.Where("id = ?", id).Preload("Players").Preload("Guesses").First(&current).Error
+	if err != nil {
+		return current, notFoundOr(err)
+	}
+	return current, LoadTeams(s.DB, &current)
+}
+
+// LoadTeams fills in the team of each player from game_players, for code
+// that loads games without a Store
+func LoadTeams(db *gorm.DB, game *models.Game) error {
+	var members []models.GamePlayer
+	if err := db.Where("game_id = ?", game.ID).Find(&members).Error; err != nil {
+		return err
+	}
+
+	game.Teams = make(map[uint]int, len(members))
+	for _, member := range members {
+		if member.Team != 0 {
+			game.Teams[member.PlayerID] = member.Team
+		}
+	}
+	return nil
+}
+
+func (s Gorm) TimedGames() ([]models.Game, error) {
+	var games []models.Game
+	states := []models.GameState{models.GameStateCountdown, models.GameStateInProgress, models.GameStateRoundOver}
+	err := s.DB.Where("state IN ?", states).Preload("Players").Find(&games).Error
+	return games, err
+}
+
+func (s Gorm) CreateGame(game *models.Game, creator *models.Player) error {
+	return s.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(game).Error; err != nil {
+			return err
+		}
+
+		creator.GameID = game.ID
+		creator.IsAdmin = true
+		return tx.Save(creator).Error
+	})
+}
+
+func (s Gorm) AddPlayer(game *models.Game, player *models.Player) error {
+	return s.DB.Transaction(func(tx *gorm.DB) error {
+		player.GameID = game.ID
+		player.IsAdmin = false
+		if err := tx.Save(player).Error; err != nil {
+			return err
+		}
+		// Appending through the game itself would also add the player to
+		// game.Players, which is up to the caller
+		return tx.Model(&models.Game{Model: gorm.Model{ID: game.ID}}).Association("Players").Append(player)
+	})
+}
+
+func (s Gorm) SetTeams(game models.Game, teams map[uint]int) error {
+	return s.DB.Transaction(func(tx *gorm.DB) error {
+		for playerID, team := range teams {
+			err := tx.Model(&models.GamePlayer{}).Where("game_id = ? AND player_id = ?", game.ID, playerID).Update("team", team).Error
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+func (s Gorm) RemovePlayer(game *models.Game, player *models.Player, admin *models.Player) error {
+	return s.DB.Transaction(func(tx *gorm.DB) error {
+		// A GameID of 0 means they are no longer in a game
+		player.GameID = 0
+		player.IsAdmin = false
+		if err := tx.Save(player).Error; err != nil {
+			return err
+		}
+
+		if err := tx.Model(&models.Game{Model: gorm.Model{ID: game.ID}}).Association("Players").Delete(player); err != nil {
+			return err
+		}
+
+		if admin != nil {
+			return tx.Model(admin).Update("is_admin", true).Error
+		}
+		return nil
+	})
+}
+
+func (s Gorm) DeleteGame(game models.Game) error {
+	return s.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Table("players").Where("game_id = ?", game.ID).Update("game_id", 0).Error; err != nil {
+			return err
+		}
+		return tx.Delete(&game).Error
+	})
+}
+
+func (s Gorm) SaveGame(game *models.Game) error {
+	return s.DB.Omit("Players", "Guesses").Save(game).Error
+}
+
+func (s Gorm) CreateGuess(guess *models.Guess) error {
+	err := s.DB.Create(guess).Error
+
+	// 23505 is unique_violation, the attempt is already taken
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) && pgErr.Code == "23505" {
+		return game.ErrDuplicate
+	}
+	return err
+}
+
+func (s Gorm) PickWord(game models.Game) (string, error) {
+	playerIDs := make([]uint, len(game.Players))
+	for i, player := range game.Players {
+		playerIDs[i] = player.ID
+	}
+
+	return wordselect.Default.Select(s.DB, wordselect.Request{
+		GameID:     game.ID,
+		PlayerIDs:  playerIDs,
+		WordLength: game.WordLength,
+	})
+}
+
+func (s Gorm) Scheduled(game models.Game) (bool, error) {
+	return wordselect.HasScheduled(s.DB, game.ID, game.WordLength)
+}
+
+func (s Gorm) FinishRound(game models.Game, round models.Round) error {
+	return s.DB.Transaction(func(tx *gorm.DB) error {
+		//Record the round before its guesses are cleared
+		if err := tx.Create(&round).Error; err != nil {
+			return errors.New("failed to save round history")
+		}
+
+		//Clear all guesses before ending game
+		if err := tx.Where("game_id = ?", game.ID).Delete(&models.Guess{}).Error; err != nil {
+			return errors.New("failed to clear guesses")
+		}
+
+		//Update game status to lobby and resetting word
+		if err := tx.Omit("Players", "Guesses").Save(&game).Error; err != nil {
+			return errors.New("failed to update game status")
+		}
+
+		for _, roundPlayer := range round.Players {
+			if _, err := stats.Recompute(tx, roundPlayer.PlayerID); err != nil {
+				return errors.New("failed to update player stats")
+			}
+		}
+
+		if err := rating.ApplyRound(tx, round); err != nil {
+			return errors.New("failed to update player ratings")
+		}
+
+		return nil
+	})
+}
+
+func notFoundOr(err error) error {
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return game.ErrNotFound
+	}
+	return err
+}
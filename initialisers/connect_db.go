@@ -2,6 +2,7 @@ package initialisers
 
 import (
 	"log"
+	"multiplayer-wordle/models"
 	"os"
 
 	"gorm.io/driver/postgres"
@@ -19,4 +20,9 @@ func ConnectDB() {
 	} else {
 		log.Println("DB Connection Established")
 	}
+
+	// Players join games through game_players, which also records their team
+	if err := DB.SetupJoinTable(&models.Game{}, "Players", &models.GamePlayer{}); err != nil {
+		log.Fatalln("Error setting up the game_players table:", err)
+	}
 }
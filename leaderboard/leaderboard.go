@@ -0,0 +1,178 @@
+package leaderboard
+
+import (
+	"errors"
+	"fmt"
+	"multiplayer-wordle/models"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+type Metric string
+
+const (
+	MetricWins       Metric = "wins"
+	MetricWinRate    Metric = "winrate"
+	MetricAvgGuesses Metric = "avg_guesses"
+	MetricStreak     Metric = "streak"
+	MetricRating     Metric = "rating"
+)
+
+type Period string
+
+const (
+	PeriodDay  Period = "day"
+	PeriodWeek Period = "week"
+	PeriodAll  Period = "all"
+)
+
+var (
+	ErrInvalidMetric = errors.New("metric must be one of wins, winrate, avg_guesses, streak, rating")
+	ErrInvalidPeriod = errors.New("period must be one of day, week, all")
+)
+
+// SQL for the ranked value of each metric and whether lower values rank higher
+var metricColumns = map[Metric]struct {
+	value     string
+	ascending bool
+	filter    string
+}{
+	MetricWins:       {value: "t.wins"},
+	MetricWinRate:    {value: "ROUND(t.wins * 100.0 / t.games_played, 2)"},
+	MetricAvgGuesses: {value: "ROUND(t.avg_guesses, 2)", ascending: true, filter: "t.wins > 0"},
+	MetricStreak:     {value: "COALESCE(s.best_streak, 0)"},
+	MetricRating:     {value: "ROUND(COALESCE(pr.rating, 1500)::numeric, 2)"},
+}
+
+type Options struct {
+	Metric Metric
+	Period Period
+	GameID uint // Only count rounds played in this game, 0 for the global board
+	Limit  int
+	Offset int
+}
+
+type Entry struct {
+	Rank        int     `json:"rank"`
+	PlayerID    uint    `json:"playerId"`
+	Username    string  `json:"username"`
+	Value       float64 `json:"value"`
+	GamesPlayed int     `json:"gamesPlayed"`
+}
+
+// Since returns the start of the period in UTC, weeks start on Monday
+func Since(period Period, now time.Time) time.Time {
+	now = now.UTC()
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+
+	switch period {
+	case PeriodDay:
+		return today
+	case PeriodWeek:
+		daysSinceMonday := (int(today.Weekday()) + 6) % 7
+		return today.AddDate(0, 0, -daysSinceMonday)
+	default:
+		return time.Time{}
+	}
+}
+
+// Query ranks players from the stored round results. Ties share a rank and are
+// ordered by games played and then username so pages are stable.
+func Query(db *gorm.DB, opts Options) ([]Entry, int64, error) {
+	column, ok := metricColumns[opts.Metric]
+	if !ok {
+		return nil, 0, ErrInvalidMetric
+	}
+
+	if opts.Period != PeriodDay && opts.Period != PeriodWeek && opts.Period != PeriodAll {
+		return nil, 0, ErrInvalidPeriod
+	}
+
+	args := map[string]interface{}{
+		"abandoned": models.RoundOutcomeAbandoned,
+		"limit":     opts.Limit,
+		"offset":    opts.Offset,
+	}
+
+	conditions := []string{"rp.deleted_at IS NULL", "r.outcome <> @abandoned"}
+	if opts.Period != PeriodAll {
+		conditions = append(conditions, "r.ended_at >= @since")
+		args["since"] = Since(opts.Period, time.Now())
+	}
+	if opts.GameID != 0 {
+		conditions = append(conditions, "r.game_id = @gameID")
+		args["gameID"] = opts.GameID
+	}
+
+	direction := "DESC"
+	if column.ascending {
+		direction = "ASC"
+	}
+
+	filter := "TRUE"
+	if column.filter != "" {
+		filter = column.filter
+	}
+
+	query := fmt.Sprintf(`
+		WITH results AS (
+			SELECT rp.player_id, rp.solved, rp.attempts_used, r.ended_at, r.id AS round_id
+			FROM round_players rp
+			JOIN rounds r ON r.id = rp.round_id AND r.deleted_at IS NULL
+			WHERE %s
+		), totals AS (
+			SELECT player_id,
+				COUNT(*) AS games_played,
+				COUNT(*) FILTER (WHERE solved) AS wins,
+				AVG(attempts_used) FILTER (WHERE solved) AS avg_guesses
+			FROM results
+			GROUP BY player_id
+		), islands AS (
+			SELECT player_id, solved,
+				ROW_NUMBER() OVER (PARTITION BY player_id ORDER BY ended_at, round_id) -
+				ROW_NUMBER() OVER (PARTITION BY player_id, solved ORDER BY ended_at, round_id) AS island
+			FROM results
+		), streaks AS (
+			SELECT player_id, MAX(length) AS best_streak
+			FROM (
+				SELECT player_id, island, COUNT(*) AS length
+				FROM islands
+				WHERE solved
+				GROUP BY player_id, island
+			) runs
+			GROUP BY player_id
+		), ranked AS (
+			SELECT t.player_id, p.username, t.games_played,
+				%[2]s AS value,
+				RANK() OVER (ORDER BY %[2]s %[3]s) AS rank,
+				COUNT(*) OVER () AS total
+			FROM totals t
+			JOIN players p ON p.id = t.player_id AND p.deleted_at IS NULL
+			LEFT JOIN streaks s ON s.player_id = t.player_id
+			LEFT JOIN player_ratings pr ON pr.player_id = t.player_id
+			WHERE %[4]s
+		)
+		SELECT * FROM ranked
+		ORDER BY rank, games_played DESC, username
+		LIMIT @limit OFFSET @offset`,
+		strings.Join(conditions, " AND "), column.value, direction, filter)
+
+	var rows []struct {
+		Entry
+		Total int64
+	}
+	if err := db.Raw(query, args).Scan(&rows).Error; err != nil {
+		return nil, 0, err
+	}
+
+	entries := make([]Entry, len(rows))
+	var total int64
+	for i, row := range rows {
+		entries[i] = row.Entry
+		total = row.Total
+	}
+
+	return entries, total, nil
+}
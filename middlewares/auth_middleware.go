@@ -1,6 +1,7 @@
 package middlewares
 
 import (
+	"errors"
 	"fmt"
 	"log"
 	"os"
@@ -12,6 +13,11 @@ import (
 
 var ignoredRoutes = []string{"/api/register", "/api/login"}
 
+var (
+	ErrInvalidToken  = errors.New("invalid or expired token")
+	ErrInvalidClaims = errors.New("invalid token claims")
+)
+
 func isIgnoredRoute(c *fiber.Ctx) bool {
 	for _, route := range ignoredRoutes {
 		if c.Path() == route {
@@ -21,6 +27,33 @@ func isIgnoredRoute(c *fiber.Ctx) bool {
 	return false
 }
 
+// ParseToken validates a JWT and returns the username in its claims
+func ParseToken(tokenString string) (string, error) {
+	token, err := jwt.Parse(tokenString, func(token *jwt.Token) (interface{}, error) {
+		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
+			return nil, fmt.Errorf("unexpected signing method: %v", token.Header["alg"])
+		}
+		return []byte(os.Getenv("JWT_SECRET")), nil
+	})
+
+	if err != nil {
+		log.Println("Token Parsing Error:", err)
+		return "", ErrInvalidToken
+	}
+
+	claims, ok := token.Claims.(jwt.MapClaims)
+	if !ok || !token.Valid {
+		return "", ErrInvalidToken
+	}
+
+	username, ok := claims["username"].(string)
+	if !ok {
+		return "", ErrInvalidClaims
+	}
+
+	return username, nil
+}
+
 func CheckAuth() fiber.Handler {
 	return func(c *fiber.Ctx) error {
 		if isIgnoredRoute(c) {
@@ -42,34 +75,19 @@ func CheckAuth() fiber.Handler {
 			})
 		}
 
-		token, err := jwt.Parse(tokenString, func(token *jwt.Token) (interface{}, error) {
-			if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
-				return nil, fmt.Errorf("unexpected signing method: %v", token.Header["alg"])
-			}
-			return []byte(os.Getenv("JWT_SECRET")), nil
-		})
-
+		username, err := ParseToken(tokenString)
+		if errors.Is(err, ErrInvalidClaims) {
+			return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+				"error": "Invalid token claims",
+			})
+		}
 		if err != nil {
-			log.Println("Token Parsing Error:", err)
 			return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
 				"error": "Invalid or expired token",
 			})
 		}
 
-		if claims, ok := token.Claims.(jwt.MapClaims); ok && token.Valid {
-			username, ok := claims["username"].(string)
-			if !ok {
-				return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
-					"error": "Invalid token claims",
-				})
-			}
-
-			c.Locals("username", username)
-			return c.Next()
-		}
-
-		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
-			"error": "Invalid token",
-		})
+		c.Locals("username", username)
+		return c.Next()
 	}
 }
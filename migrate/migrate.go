@@ -1,8 +1,10 @@
 package main
 
 import (
+	"log"
 	"multiplayer-wordle/initialisers"
 	"multiplayer-wordle/models"
+	"multiplayer-wordle/stats"
 )
 
 func init() {
@@ -11,5 +13,20 @@ func init() {
 }
 
 func main() {
-	initialisers.DB.AutoMigrate(&models.Player{}, &models.Game{}, &models.Guess{})
+	// Guesses are unique per attempt now, drop duplicates a race let in so the index can be built
+	if initialisers.DB.Migrator().HasTable(&models.Guess{}) {
+		err := initialisers.DB.Exec(`DELETE FROM guesses WHERE deleted_at IS NULL AND id NOT IN (
+			SELECT MIN(id) FROM guesses WHERE deleted_at IS NULL GROUP BY game_id, player_id, attempt_number
+		)`).Error
+		if err != nil {
+			log.Println("Failed to remove duplicate guesses:", err)
+		}
+	}
+
+	initialisers.DB.AutoMigrate(&models.Player{}, &models.Game{}, &models.Guess{}, &models.Round{}, &models.RoundPlayer{}, &models.RoundGuess{}, &models.PlayerStats{}, &models.PlayerRating{}, &models.RatingHistory{}, &models.DailyGuess{}, &models.DailyResult{}, &models.ScheduledWord{}, &models.GameEventSequence{}, &models.GameEvent{}, &models.GamePlayer{}, &models.PlayerPresence{})
+
+	// Stats are derived from the round history, rebuild them in case the aggregation changed
+	if err := stats.RecomputeAll(initialisers.DB); err != nil {
+		log.Println("Failed to recompute player stats:", err)
+	}
 }
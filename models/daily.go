@@ -0,0 +1,29 @@
+package models
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// DailyGuess is a guess made in the daily challenge, each player gets one
+// attempt sequence per date
+type DailyGuess struct {
+	gorm.Model
+	PlayerID      uint   `gorm:"not null; uniqueIndex:idx_daily_guess_attempt" json:"playerId"`
+	Date          string `gorm:"not null; uniqueIndex:idx_daily_guess_attempt" json:"date"`
+	GuessWord     string `gorm:"not null" json:"guessWord"`
+	Feedback      string `gorm:"not null" json:"feedback"`
+	AttemptNumber uint   `gorm:"not null; uniqueIndex:idx_daily_guess_attempt" json:"attemptNumber"`
+}
+
+// DailyResult is written once a player solves or runs out of attempts
+type DailyResult struct {
+	gorm.Model
+	PlayerID     uint      `gorm:"not null; uniqueIndex:idx_daily_result_player" json:"playerId"`
+	Date         string    `gorm:"not null; uniqueIndex:idx_daily_result_player; index" json:"date"`
+	Username     string    `gorm:"not null" json:"username"`
+	Solved       bool      `gorm:"not null; default:false" json:"solved"`
+	AttemptsUsed uint      `gorm:"not null" json:"attemptsUsed"`
+	CompletedAt  time.Time `gorm:"not null" json:"completedAt"`
+}
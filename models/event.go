@@ -0,0 +1,28 @@
+package models
+
+import "time"
+
+// GameEventSequence is the last broadcast sequence number handed out in a
+// game, shared by every server instance
+type GameEventSequence struct {
+	GameID uint   `gorm:"primaryKey; autoIncrement:false" json:"gameId"`
+	Seq    uint64 `gorm:"not null; default:0" json:"seq"`
+}
+
+// GameEvent holds a broadcast too large for a NOTIFY payload until every
+// instance has read it
+type GameEvent struct {
+	ID        uint      `gorm:"primaryKey" json:"id"`
+	Payload   string    `gorm:"type:text; not null" json:"payload"`
+	CreatedAt time.Time `gorm:"index" json:"createdAt"`
+}
+
+// PlayerPresence is one server instance holding connections of a player to a
+// game. The instance refreshes SeenAt while it runs, a row that stops being
+// refreshed belongs to an instance that went away
+type PlayerPresence struct {
+	InstanceID string    `gorm:"primaryKey" json:"instanceId"`
+	GameID     uint      `gorm:"primaryKey; autoIncrement:false" json:"gameId"`
+	Username   string    `gorm:"primaryKey" json:"username"`
+	SeenAt     time.Time `gorm:"index; not null" json:"seenAt"`
+}
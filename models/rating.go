@@ -0,0 +1,29 @@
+package models
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// PlayerRating is a player's current Glicko skill estimate
+type PlayerRating struct {
+	PlayerID        uint       `gorm:"primaryKey" json:"playerId"`
+	Rating          float64    `gorm:"not null; default:1500" json:"rating"`
+	RatingDeviation float64    `gorm:"not null; default:350" json:"ratingDeviation"`
+	RoundsRated     int        `gorm:"not null; default:0" json:"roundsRated"`
+	LastRatedAt     *time.Time `json:"lastRatedAt"`
+	UpdatedAt       time.Time  `json:"updatedAt"`
+}
+
+// RatingHistory records every rating change caused by a round
+type RatingHistory struct {
+	gorm.Model
+	PlayerID     uint    `gorm:"not null; index" json:"playerId"`
+	RoundID      uint    `gorm:"not null; index" json:"roundId"`
+	Placement    int     `gorm:"not null" json:"placement"` // 1 is the best finish in the round
+	RatingBefore float64 `gorm:"not null" json:"ratingBefore"`
+	RatingAfter  float64 `gorm:"not null" json:"ratingAfter"`
+	RDBefore     float64 `gorm:"not null" json:"rdBefore"`
+	RDAfter      float64 `gorm:"not null" json:"rdAfter"`
+}
@@ -0,0 +1,56 @@
+package models
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Round is the permanent record of a single word played in a game
+type Round struct {
+	gorm.Model
+	GameID       uint          `gorm:"not null; index" json:"gameId"`
+	Word         string        `gorm:"not null" json:"word"`
+	WordLength   int           `gorm:"not null" json:"wordLength"`
+	HardMode     bool          `gorm:"not null; default:false" json:"hardMode"`
+	StartedAt    time.Time     `gorm:"not null" json:"startedAt"`
+	EndedAt      time.Time     `gorm:"not null; index" json:"endedAt"`
+	WinnerID     *uint         `json:"winnerId"`
+	GameMasterID *uint         `json:"gameMasterId"` // Set when the word was chosen by the host
+	Outcome      RoundOutcome  `gorm:"not null" json:"outcome"`
+	MatchRound   int           `gorm:"not null; default:1" json:"matchRound"` // Which round of its match this was
+	Players      []RoundPlayer `gorm:"foreignkey:RoundID;constraint:OnDelete:CASCADE;" json:"players"`
+	Guesses      []RoundGuess  `gorm:"foreignkey:RoundID;constraint:OnDelete:CASCADE;" json:"guesses"`
+}
+
+// RoundPlayer records how a single player did in a round
+type RoundPlayer struct {
+	gorm.Model
+	RoundID      uint       `gorm:"not null; index" json:"roundId"`
+	PlayerID     uint       `gorm:"not null; index" json:"playerId"`
+	Username     string     `gorm:"not null" json:"username"`
+	Solved       bool       `gorm:"not null; default:false" json:"solved"`
+	SolvedAt     *time.Time `json:"solvedAt"`
+	AttemptsUsed uint       `gorm:"not null" json:"attemptsUsed"`
+	Points       int        `gorm:"not null; default:0" json:"points"` // Match points earned this round
+}
+
+// RoundGuess is a guess copied out of the live guesses table when a round ends
+type RoundGuess struct {
+	gorm.Model
+	RoundID       uint   `gorm:"not null; index" json:"roundId"`
+	PlayerID      uint   `gorm:"not null" json:"playerId"`
+	GuessWord     string `gorm:"not null" json:"guessWord"`
+	Feedback      string `gorm:"not null" json:"feedback"`
+	AttemptNumber uint   `gorm:"not null" json:"attemptNumber"`
+}
+
+// RoundOutcome describes how a round ended
+type RoundOutcome string
+
+const (
+	RoundOutcomeWon       RoundOutcome = "won"       // Someone guessed the word
+	RoundOutcomeLost      RoundOutcome = "lost"      // Everyone ran out of attempts
+	RoundOutcomeAbandoned RoundOutcome = "abandoned" // Players left before the round finished
+	RoundOutcomeTimeUp    RoundOutcome = "time-up"   // The race clock ran out before everyone finished
+)
@@ -0,0 +1,16 @@
+package models
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// ScheduledWord is a word the lobby admin queued up for a future round
+type ScheduledWord struct {
+	gorm.Model
+	GameID   uint       `gorm:"not null; index" json:"gameId"`
+	Position int        `gorm:"not null" json:"position"`
+	Word     string     `gorm:"not null" json:"word"`
+	UsedAt   *time.Time `json:"usedAt"`
+}
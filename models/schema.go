@@ -1,6 +1,10 @@
 package models
 
-import "gorm.io/gorm"
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
 
 type Player struct {
 	gorm.Model
@@ -12,25 +16,66 @@ type Player struct {
 
 type Game struct {
 	gorm.Model
-	Word    string    `gorm:"not null" json:"word"` // Secret word
-	State   GameState `gorm:"not null; default:lobby" json:"state"`
-	Players []Player  `gorm:"many2many:game_players;constraint:OnDelete:CASCADE;" json:"players"` // Many-to-many relation with players
-	Guesses []Guess   `gorm:"foreignkey:GameID;constraint:OnDelete:CASCADE;" json:"guesses"`      // Guesses made during the game
+	Word           string                  `gorm:"not null" json:"word"` // Secret word
+	WordLength     int                     `gorm:"not null; default:5" json:"wordLength"`
+	HardMode       bool                    `gorm:"not null; default:false" json:"hardMode"` // Revealed hints must be used in later guesses
+	StartedAt      *time.Time              `json:"startedAt"`                               // When the current round started
+	GameMasterID   *uint                   `json:"gameMasterId"`                            // Admin who chose the word and is not guessing this round
+	State          GameState               `gorm:"not null; default:lobby" json:"state"`
+	StateChangedAt map[GameState]time.Time `gorm:"serializer:json" json:"stateChangedAt"`  // When the game last entered each state
+	Rounds         int                     `gorm:"not null; default:1" json:"rounds"`      // Rounds in a match, chosen when the game is created
+	RoundNumber    int                     `gorm:"not null; default:0" json:"roundNumber"` // Round of the current match, 0 before the first one
+	Scores         map[uint]int            `gorm:"serializer:json" json:"scores"`          // Match points so far by player ID
+	Mode           GameMode                `gorm:"not null; default:classic" json:"mode"`
+	RoundSeconds   int                     `gorm:"not null; default:0" json:"roundSeconds"`                            // Time limit of each round in race mode
+	Deadline       *time.Time              `json:"deadline"`                                                           // When the current race round runs out of time
+	TickedAt       *time.Time              `json:"-"`                                                                  // Last clock update of the race round, shared by every instance
+	Eliminated     []uint                  `gorm:"serializer:json" json:"eliminated"`                                  // Players knocked out of a battle royale, they stay on as spectators
+	SharedAttempts int                     `gorm:"not null; default:0" json:"sharedAttempts"`                          // Attempts the whole team shares in co-op mode
+	Teams          map[uint]int            `gorm:"-" json:"teams"`                                                     // Team of each player by ID in a team game, loaded from game_players
+	Players        []Player                `gorm:"many2many:game_players;constraint:OnDelete:CASCADE;" json:"players"` // Many-to-many relation with players
+	Guesses        []Guess                 `gorm:"foreignkey:GameID;constraint:OnDelete:CASCADE;" json:"guesses"`      // Guesses made during the game
+}
+
+// GamePlayer is the game_players join table, with the team the player is on
+type GamePlayer struct {
+	GameID   uint `gorm:"primaryKey" json:"gameId"`
+	PlayerID uint `gorm:"primaryKey" json:"playerId"`
+	Team     int  `gorm:"not null; default:0" json:"team"` // 0 when the game isn't played in teams
+	// Set while the player has no connection to the game left, they are
+	// removed once it is older than the grace period
+	DisconnectedAt *time.Time `gorm:"index" json:"disconnectedAt"`
 }
 
 type Guess struct {
 	gorm.Model
-	GameID        uint   `gorm:"not null" json:"gameId"`
-	PlayerID      uint   `gorm:"not null" json:"playerId"`
+	GameID        uint   `gorm:"not null; uniqueIndex:idx_guess_attempt,where:deleted_at IS NULL" json:"gameId"` // One live guess per attempt, cleared guesses are soft deleted
+	PlayerID      uint   `gorm:"not null; uniqueIndex:idx_guess_attempt" json:"playerId"`
 	GuessWord     string `gorm:"not null" json:"guessWord"`
 	Feedback      string `gorm:"not null" json:"feedback"`
-	AttemptNumber uint   `gorm:"not null" json:"attemptNumber"`
+	AttemptNumber uint   `gorm:"not null; uniqueIndex:idx_guess_attempt" json:"attemptNumber"`
 }
 
-// GameState type defines possible game states
+// GameMode picks the rules a game is played by
+type GameMode string
+
+const (
+	GameModeClassic     GameMode = "classic"     // The first player to solve the word wins the round
+	GameModeRace        GameMode = "race"        // Everyone races the clock, faster solves score more
+	GameModeElimination GameMode = "elimination" // Battle royale, the worst players of each round are knocked out until one is left
+	GameModeCoop        GameMode = "coop"        // Everyone plays one shared board and wins or loses together
+	GameModeTeams       GameMode = "teams"       // Two teams race each other, the first team to solve the word wins
+)
+
+// GameState type defines possible game states, see game.Transition for the
+// moves allowed between them
 type GameState string
 
 const (
-	GameStateInProgress GameState = "in-progress"
-	GameStateFinished   GameState = "lobby"
+	GameStateLobby      GameState = "lobby"       // Waiting for the admin to start a round
+	GameStateCountdown  GameState = "countdown"   // A round is about to start
+	GameStateInProgress GameState = "in-progress" // Players are guessing
+	GameStateRoundOver  GameState = "round-over"  // The last round ended, results are showing
+	GameStateFinished   GameState = "finished"    // The match is over
+	GameStateAbandoned  GameState = "abandoned"   // Players left before the round could finish
 )
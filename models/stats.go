@@ -0,0 +1,17 @@
+package models
+
+import "time"
+
+// PlayerStats is a cached aggregate of a player's round history, it can always
+// be rebuilt from the RoundPlayer rows
+type PlayerStats struct {
+	PlayerID          uint      `gorm:"primaryKey" json:"playerId"`
+	GamesPlayed       int       `gorm:"not null; default:0" json:"gamesPlayed"`
+	Wins              int       `gorm:"not null; default:0" json:"wins"`
+	Losses            int       `gorm:"not null; default:0" json:"losses"`
+	WinPercentage     float64   `gorm:"not null; default:0" json:"winPercentage"`
+	CurrentStreak     int       `gorm:"not null; default:0" json:"currentStreak"`
+	MaxStreak         int       `gorm:"not null; default:0" json:"maxStreak"`
+	GuessDistribution []int     `gorm:"serializer:json" json:"guessDistribution"` // Index 0 is a win in 1 guess
+	UpdatedAt         time.Time `json:"updatedAt"`
+}
@@ -0,0 +1,97 @@
+package rating
+
+import (
+	"math"
+	"time"
+)
+
+const (
+	InitialRating    = 1500.0
+	InitialDeviation = 350.0
+	MinDeviation     = 30.0
+
+	// How much uncertainty returns for every idle day, a player who hasn't
+	// played for 100 days is back at the initial deviation
+	deviationDecayPerDay = 34.6
+)
+
+var q = math.Ln10 / 400
+
+// Participant is one player's rating going into a round and their placement,
+// equal placements are treated as draws
+type Participant struct {
+	PlayerID  uint
+	Rating    float64
+	Deviation float64
+	Placement int
+}
+
+type Result struct {
+	PlayerID  uint
+	Rating    float64
+	Deviation float64
+}
+
+func g(deviation float64) float64 {
+	return 1 / math.Sqrt(1+3*q*q*deviation*deviation/(math.Pi*math.Pi))
+}
+
+func expectedScore(rating, opponentRating, opponentDeviation float64) float64 {
+	return 1 / (1 + math.Pow(10, -g(opponentDeviation)*(rating-opponentRating)/400))
+}
+
+// InflateDeviation grows a deviation for the time a player hasn't been rated
+func InflateDeviation(deviation float64, lastRatedAt *time.Time, now time.Time) float64 {
+	if lastRatedAt == nil {
+		return deviation
+	}
+
+	days := now.Sub(*lastRatedAt).Hours() / 24
+	if days <= 0 {
+		return deviation
+	}
+
+	inflated := math.Sqrt(deviation*deviation + deviationDecayPerDay*deviationDecayPerDay*days)
+	return math.Min(inflated, InitialDeviation)
+}
+
+// Update rates a free-for-all round as a set of pairwise games played in a
+// single Glicko rating period
+func Update(participants []Participant) []Result {
+	results := make([]Result, len(participants))
+
+	for i, player := range participants {
+		var variance, improvement float64
+
+		for j, opponent := range participants {
+			if i == j {
+				continue
+			}
+
+			score := 0.5
+			if player.Placement < opponent.Placement {
+				score = 1
+			} else if player.Placement > opponent.Placement {
+				score = 0
+			}
+
+			gOpponent := g(opponent.Deviation)
+			expected := expectedScore(player.Rating, opponent.Rating, opponent.Deviation)
+			variance += gOpponent * gOpponent * expected * (1 - expected)
+			improvement += gOpponent * (score - expected)
+		}
+
+		results[i] = Result{PlayerID: player.PlayerID, Rating: player.Rating, Deviation: player.Deviation}
+		if variance == 0 {
+			continue
+		}
+
+		dSquared := 1 / (q * q * variance)
+		precision := 1/(player.Deviation*player.Deviation) + 1/dSquared
+
+		results[i].Rating = player.Rating + q/precision*improvement
+		results[i].Deviation = math.Max(math.Sqrt(1/precision), MinDeviation)
+	}
+
+	return results
+}
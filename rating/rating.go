@@ -0,0 +1,117 @@
+package rating
+
+import (
+	"multiplayer-wordle/models"
+	"sort"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Placements ranks the players of a round. Solvers come first, ordered by
+// attempts and then solve time, everyone who failed to solve shares last place.
+func Placements(players []models.RoundPlayer) map[uint]int {
+	ordered := make([]models.RoundPlayer, len(players))
+	copy(ordered, players)
+
+	better := func(a, b models.RoundPlayer) bool {
+		if a.Solved != b.Solved {
+			return a.Solved
+		}
+		if !a.Solved {
+			return false
+		}
+		if a.AttemptsUsed != b.AttemptsUsed {
+			return a.AttemptsUsed < b.AttemptsUsed
+		}
+		if a.SolvedAt != nil && b.SolvedAt != nil {
+			return a.SolvedAt.Before(*b.SolvedAt)
+		}
+		return false
+	}
+
+	sort.SliceStable(ordered, func(i, j int) bool {
+		return better(ordered[i], ordered[j])
+	})
+
+	placements := make(map[uint]int)
+	for i, player := range ordered {
+		if i > 0 && !better(ordered[i-1], player) {
+			placements[player.PlayerID] = placements[ordered[i-1].PlayerID]
+		} else {
+			placements[player.PlayerID] = i + 1
+		}
+	}
+
+	return placements
+}
+
+// Get returns a player's rating, or the initial rating if they have never been rated
+func Get(db *gorm.DB, playerID uint) (models.PlayerRating, error) {
+	playerRating := models.PlayerRating{
+		PlayerID:        playerID,
+		Rating:          InitialRating,
+		RatingDeviation: InitialDeviation,
+	}
+
+	err := db.Where("player_id = ?", playerID).Limit(1).Find(&playerRating).Error
+	return playerRating, err
+}
+
+// ApplyRound updates the ratings of everyone who played a finished round and
+// records the change in the rating history
+func ApplyRound(db *gorm.DB, round models.Round) error {
+	if round.Outcome == models.RoundOutcomeAbandoned || len(round.Players) < 2 {
+		return nil
+	}
+
+	placements := Placements(round.Players)
+	current := make(map[uint]models.PlayerRating)
+	participants := make([]Participant, 0, len(round.Players))
+
+	for _, player := range round.Players {
+		playerRating, err := Get(db, player.PlayerID)
+		if err != nil {
+			return err
+		}
+		current[player.PlayerID] = playerRating
+
+		participants = append(participants, Participant{
+			PlayerID:  player.PlayerID,
+			Rating:    playerRating.Rating,
+			Deviation: InflateDeviation(playerRating.RatingDeviation, playerRating.LastRatedAt, round.EndedAt),
+			Placement: placements[player.PlayerID],
+		})
+	}
+
+	ratedAt := time.Now()
+	for i, result := range Update(participants) {
+		before := current[result.PlayerID]
+
+		history := models.RatingHistory{
+			PlayerID:     result.PlayerID,
+			RoundID:      round.ID,
+			Placement:    participants[i].Placement,
+			RatingBefore: before.Rating,
+			RatingAfter:  result.Rating,
+			RDBefore:     participants[i].Deviation,
+			RDAfter:      result.Deviation,
+		}
+		if err := db.Create(&history).Error; err != nil {
+			return err
+		}
+
+		after := models.PlayerRating{
+			PlayerID:        result.PlayerID,
+			Rating:          result.Rating,
+			RatingDeviation: result.Deviation,
+			RoundsRated:     before.RoundsRated + 1,
+			LastRatedAt:     &ratedAt,
+		}
+		if err := db.Save(&after).Error; err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
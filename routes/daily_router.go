@@ -0,0 +1,13 @@
+package routes
+
+import (
+	"multiplayer-wordle/controllers"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+func DailyRouter(api fiber.Router) {
+	api.Get("/daily", controllers.GetDaily)
+	api.Post("/daily/guess", controllers.DailyGuess)
+	api.Get("/daily/leaderboard", controllers.GetDailyLeaderboard)
+}
@@ -12,6 +12,9 @@ func GameRouter(api fiber.Router) {
 	api.Patch("/game/:gameID/join", controllers.JoinGame)
 	api.Patch("/game/:gameID/leave", controllers.LeaveGame)
 	api.Patch("/game/:gameID/start", controllers.StartGame)
+	api.Put("/game/:gameID/teams", controllers.UpdateTeams)
 	api.Post("/game/:gameID/guess", controllers.GuessWord)
+	api.Get("/game/:gameID/schedule", controllers.GetWordSchedule)
+	api.Put("/game/:gameID/schedule", controllers.UpdateWordSchedule)
 
 }
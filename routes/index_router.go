@@ -13,4 +13,8 @@ func IndexRouter(api fiber.Router) {
 	// Add authentication-related routes
 	AuthRouter(api)
 	GameRouter(api)
+	RoundRouter(api)
+	PlayerRouter(api)
+	LeaderboardRouter(api)
+	DailyRouter(api)
 }
@@ -0,0 +1,11 @@
+package routes
+
+import (
+	"multiplayer-wordle/controllers"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+func LeaderboardRouter(api fiber.Router) {
+	api.Get("/leaderboard", controllers.GetLeaderboard)
+}
@@ -0,0 +1,12 @@
+package routes
+
+import (
+	"multiplayer-wordle/controllers"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+func PlayerRouter(api fiber.Router) {
+	api.Get("/players/:username/stats", controllers.GetPlayerStats)
+	api.Get("/players/:username/rating", controllers.GetPlayerRating)
+}
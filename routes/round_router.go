@@ -0,0 +1,12 @@
+package routes
+
+import (
+	"multiplayer-wordle/controllers"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+func RoundRouter(api fiber.Router) {
+	api.Get("/game/:gameID/rounds", controllers.GetGameRounds)
+	api.Get("/me/history", controllers.GetMyHistory)
+}
@@ -1,10 +1,15 @@
 package main
 
 import (
+	"log"
+	"multiplayer-wordle/controllers"
+	"multiplayer-wordle/dictionary"
+	"multiplayer-wordle/game"
 	"multiplayer-wordle/initialisers"
 	"multiplayer-wordle/middlewares"
 	"multiplayer-wordle/routes"
 	"multiplayer-wordle/websockets"
+	"multiplayer-wordle/wordselect"
 	"os"
 	"time"
 
@@ -19,6 +24,48 @@ import (
 func init() {
 	initialisers.LoadEnv()
 	initialisers.ConnectDB()
+	dictionary.Load()
+
+	// Optionally avoid repeating words a player has already seen in other games
+	wordselect.Default = wordselect.Scheduled{
+		Fallback: wordselect.NoRepeat{PerPlayer: os.Getenv("WORD_ROTATION_PER_PLAYER") == "true"},
+	}
+
+	// Share WebSocket events between instances through Postgres, needed when running more than one
+	if os.Getenv("WEBSOCKET_BROKER") == "postgres" {
+		broker := websockets.NewPostgresBroker(initialisers.DB, os.Getenv("DATABASE_URL"))
+		if err := websockets.Hub.UseBroker(broker); err != nil {
+			log.Fatalln("Error starting the WebSocket broker:", err)
+		}
+	}
+
+	// Countdown before each round, e.g. "5s" or "0" to start rounds straight away
+	if value := os.Getenv("ROUND_COUNTDOWN"); value != "" {
+		if countdown, err := time.ParseDuration(value); err == nil {
+			game.DefaultCountdown = countdown
+		}
+	}
+
+	// How often the time left in a race round is broadcast, e.g. "5s"
+	if value := os.Getenv("ROUND_TICK"); value != "" {
+		if tick, err := time.ParseDuration(value); err == nil && tick > 0 {
+			game.DefaultRoundTick = tick
+		}
+	}
+
+	// Break between the rounds of a match, e.g. "10s" or "0" to count down to the next round straight away
+	if value := os.Getenv("ROUND_BREAK"); value != "" {
+		if roundBreak, err := time.ParseDuration(value); err == nil {
+			game.DefaultRoundBreak = roundBreak
+		}
+	}
+
+	// How long a disconnected player keeps their seat, e.g. "90s" or "0" to never remove them
+	if value := os.Getenv("DISCONNECT_GRACE_PERIOD"); value != "" {
+		if grace, err := time.ParseDuration(value); err == nil {
+			controllers.DisconnectGracePeriod = grace
+		}
+	}
 }
 
 func main() {
@@ -87,8 +134,15 @@ func setupWebSocketRoutes(app *fiber.App) {
 		return fiber.ErrUpgradeRequired
 	})
 
+	// Game actions clients can send over the socket instead of REST
+	controllers.RegisterSocketCommands()
+	controllers.RegisterPresenceHandlers()
+	websockets.Hub.StartPresence()
+	controllers.ResumeGameTimers()
+
 	app.Get("/ws/:gameID", websocket.New(websockets.Hub.HandleConnection, websocket.Config{
-		Origins: []string{"http://localhost:3000", "http://localhost:5173", "https://multiplayer-wordle-production.up.railway.app", "https://wordle.actuallyakshat.in"},
+		Subprotocols: []string{websockets.TokenSubprotocol},
+		Origins:      []string{"http://localhost:3000", "http://localhost:5173", "https://multiplayer-wordle-production.up.railway.app", "https://wordle.actuallyakshat.in"},
 	}))
 }
 
@@ -0,0 +1,100 @@
+package stats
+
+import (
+	"multiplayer-wordle/constants"
+	"multiplayer-wordle/models"
+
+	"gorm.io/gorm"
+)
+
+// Compute aggregates a player's round results, which must be ordered from
+// oldest to newest for the streaks to be correct
+func Compute(playerID uint, results []models.RoundPlayer) models.PlayerStats {
+	stats := models.PlayerStats{
+		PlayerID:          playerID,
+		GuessDistribution: make([]int, constants.MaxAttempts),
+	}
+
+	for _, result := range results {
+		stats.GamesPlayed++
+
+		if !result.Solved {
+			stats.Losses++
+			stats.CurrentStreak = 0
+			continue
+		}
+
+		stats.Wins++
+		stats.CurrentStreak++
+		if stats.CurrentStreak > stats.MaxStreak {
+			stats.MaxStreak = stats.CurrentStreak
+		}
+
+		attempts := int(result.AttemptsUsed)
+		if attempts < 1 {
+			attempts = 1
+		}
+		if attempts > constants.MaxAttempts {
+			attempts = constants.MaxAttempts
+		}
+		stats.GuessDistribution[attempts-1]++
+	}
+
+	if stats.GamesPlayed > 0 {
+		stats.WinPercentage = float64(stats.Wins) * 100 / float64(stats.GamesPlayed)
+	}
+
+	return stats
+}
+
+// Recompute rebuilds a player's stats from their finished rounds and saves them.
+// Abandoned rounds are not counted.
+func Recompute(db *gorm.DB, playerID uint) (models.PlayerStats, error) {
+	var results []models.RoundPlayer
+	if err := db.
+		Joins("JOIN rounds ON rounds.id = round_players.round_id AND rounds.deleted_at IS NULL").
+		Where("round_players.player_id = ? AND rounds.outcome <> ?", playerID, models.RoundOutcomeAbandoned).
+		Order("rounds.ended_at, rounds.id").
+		Find(&results).Error; err != nil {
+		return models.PlayerStats{}, err
+	}
+
+	stats := Compute(playerID, results)
+	if err := db.Save(&stats).Error; err != nil {
+		return models.PlayerStats{}, err
+	}
+
+	return stats, nil
+}
+
+// RecomputeAll rebuilds the stats of every player, used after the
+// aggregation logic changes
+func RecomputeAll(db *gorm.DB) error {
+	var playerIDs []uint
+	if err := db.Model(&models.Player{}).Pluck("id", &playerIDs).Error; err != nil {
+		return err
+	}
+
+	for _, playerID := range playerIDs {
+		if _, err := Recompute(db, playerID); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// Get returns the saved stats of a player, building them if they don't exist yet
+func Get(db *gorm.DB, playerID uint) (models.PlayerStats, error) {
+	var stats models.PlayerStats
+	err := db.Where("player_id = ?", playerID).Limit(1).Find(&stats).Error
+	if err != nil {
+		return models.PlayerStats{}, err
+	}
+
+	if stats.PlayerID == 0 {
+		return Recompute(db, playerID)
+	}
+
+	return stats, nil
+}
@@ -0,0 +1,167 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"math/rand"
+	"net/http"
+	"os"
+	"sync"
+	"time"
+)
+
+// Hammers one game with joins, guesses and leaves in parallel against a
+// running server. The API allows 50 requests a minute per IP, this stays under
+const (
+	baseURL = "http://localhost:8080/api"
+	players = 6
+)
+
+var failed bool
+
+func main() {
+	suffix := rand.New(rand.NewSource(time.Now().UnixNano())).Intn(1000000)
+	tokens := make([]string, players)
+	for i := range tokens {
+		username := fmt.Sprintf("race%d_%d", suffix, i)
+		credentials := map[string]string{"username": username, "password": "password"}
+		if status, body := request("POST", "/register", "", credentials); status != http.StatusOK {
+			fmt.Printf("Failed to register %s: %d %v\n", username, status, body)
+			os.Exit(1)
+		}
+		status, body := request("POST", "/login", "", credentials)
+		if status != http.StatusOK {
+			fmt.Printf("Failed to log in %s: %d %v\n", username, status, body)
+			os.Exit(1)
+		}
+		tokens[i] = body["jwt"].(string)
+	}
+
+	status, body := request("POST", "/game", tokens[0], nil)
+	if status != http.StatusCreated {
+		fmt.Printf("Failed to create a game: %d %v\n", status, body)
+		os.Exit(1)
+	}
+	gameID := uint(body["game"].(map[string]interface{})["ID"].(float64))
+	fmt.Println("Created game", gameID)
+
+	// Every player joins twice at once, exactly one of each pair may get in
+	joins := parallel(players-1, 2, func(player, _ int) int {
+		status, _ := request("PATCH", fmt.Sprintf("/game/%d/join", gameID), tokens[player+1], nil)
+		return status
+	})
+	for player, statuses := range joins {
+		check(successes(statuses) == 1, "player %d joined %d times: %v", player+1, successes(statuses), statuses)
+	}
+
+	status, body = request("GET", fmt.Sprintf("/game/%d", gameID), tokens[0], nil)
+	gamePlayers, _ := body["game"].(map[string]interface{})["players"].([]interface{})
+	check(status == http.StatusOK && len(gamePlayers) == players, "game has %d players, expected %d", len(gamePlayers), players)
+
+	if status, body := request("PATCH", fmt.Sprintf("/game/%d/start", gameID), tokens[0], nil); status != http.StatusOK {
+		fmt.Printf("Failed to start the game: %d %v\n", status, body)
+		os.Exit(1)
+	}
+	// Wait out the countdown before guessing
+	time.Sleep(4 * time.Second)
+
+	// Every player sends the same first attempt twice at once, only one of
+	// each pair may be stored
+	guesses := parallel(players, 2, func(player, _ int) int {
+		guess := map[string]interface{}{"guessWord": "crane", "attemptNumber": 0}
+		status, _ := request("POST", fmt.Sprintf("/game/%d/guess", gameID), tokens[player], guess)
+		return status
+	})
+	for player, statuses := range guesses {
+		check(successes(statuses) <= 1, "player %d made attempt 0 %d times: %v", player, successes(statuses), statuses)
+	}
+
+	// Everyone leaves at once, the game must be deleted exactly when the last one is out
+	leaves := parallel(players, 1, func(player, _ int) int {
+		status, _ := request("PATCH", fmt.Sprintf("/game/%d/leave", gameID), tokens[player], nil)
+		return status
+	})
+	for player, statuses := range leaves {
+		check(successes(statuses) == 1, "player %d failed to leave: %v", player, statuses)
+	}
+
+	status, _ = request("GET", fmt.Sprintf("/game/%d", gameID), tokens[0], nil)
+	check(status == http.StatusNotFound, "game still exists after everyone left: %d", status)
+
+	if failed {
+		os.Exit(1)
+	}
+	fmt.Println("All checks passed")
+}
+
+// parallel runs fn for every player the given number of times, all at once,
+// and returns the statuses per player
+func parallel(count, times int, fn func(player, attempt int) int) [][]int {
+	statuses := make([][]int, count)
+	for i := range statuses {
+		statuses[i] = make([]int, times)
+	}
+
+	var wg sync.WaitGroup
+	start := make(chan struct{})
+	for player := 0; player < count; player++ {
+		for attempt := 0; attempt < times; attempt++ {
+			wg.Add(1)
+			go func(player, attempt int) {
+				defer wg.Done()
+				<-start
+				statuses[player][attempt] = fn(player, attempt)
+			}(player, attempt)
+		}
+	}
+	close(start)
+	wg.Wait()
+
+	return statuses
+}
+
+func request(method, path, token string, payload interface{}) (int, map[string]interface{}) {
+	var body bytes.Buffer
+	if payload != nil {
+		json.NewEncoder(&body).Encode(payload)
+	}
+
+	req, err := http.NewRequest(method, baseURL+path, &body)
+	if err != nil {
+		fmt.Println("Failed to build request:", err)
+		os.Exit(1)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		fmt.Printf("%s %s failed: %v\n", method, path, err)
+		return 0, nil
+	}
+	defer resp.Body.Close()
+
+	var decoded map[string]interface{}
+	json.NewDecoder(resp.Body).Decode(&decoded)
+	return resp.StatusCode, decoded
+}
+
+func successes(statuses []int) int {
+	count := 0
+	for _, status := range statuses {
+		if status >= 200 && status < 300 {
+			count++
+		}
+	}
+	return count
+}
+
+func check(ok bool, format string, args ...interface{}) {
+	if !ok {
+		failed = true
+		fmt.Printf("FAIL: "+format+"\n", args...)
+	}
+}
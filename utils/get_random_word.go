@@ -5,8 +5,12 @@ import (
 	"multiplayer-wordle/constants"
 )
 
-func GetRandomWord() string {
-	randomIndex := rand.Intn(len(constants.WordList))
-	randomWord := constants.WordList[randomIndex]
+func GetRandomWord(length int) string {
+	wordList, ok := constants.WordLists[length]
+	if !ok {
+		wordList = constants.WordList
+	}
+	randomIndex := rand.Intn(len(wordList))
+	randomWord := wordList[randomIndex]
 	return randomWord
 }
@@ -0,0 +1,101 @@
+package websockets
+
+import (
+	"encoding/json"
+	"sync"
+)
+
+// Event is a message on its way to the connections of a game
+type Event struct {
+	GameID   uint            `json:"gameId"`
+	Seq      uint64          `json:"seq,omitempty"`      // Zero for messages to a single player, those aren't logged
+	Username string          `json:"username,omitempty"` // When set, only this player's connections get the event
+	Data     json.RawMessage `json:"data,omitempty"`
+	// A broadcast only these players get, like a team's guesses. It is
+	// sequenced and logged like any other, and only replayed to them
+	Recipients []string `json:"recipients,omitempty"`
+	// Disconnect closes the player's connections instead of sending them Data
+	Disconnect bool `json:"disconnect,omitempty"`
+}
+
+// sequenced reports whether the event gets a sequence number and is logged
+func (e Event) sequenced() bool {
+	return e.Username == ""
+}
+
+// reaches reports whether a player's connections get the event
+func (e Event) reaches(username string) bool {
+	if e.Username != "" {
+		return e.Username == username
+	}
+	return isRecipient(e.Recipients, username)
+}
+
+// isRecipient reports whether the player is among the recipients, everyone
+// is when there are none
+func isRecipient(recipients []string, username string) bool {
+	if len(recipients) == 0 {
+		return true
+	}
+	for _, recipient := range recipients {
+		if recipient == username {
+			return true
+		}
+	}
+	return false
+}
+
+// Broker carries events between every server instance. Each instance
+// subscribes once and fans the events out to its own connections
+type Broker interface {
+	// Publish sends an event to all instances. Broadcasts (no username) get
+	// the game's next sequence number, which build receives to encode the
+	// message. build is nil when the event carries no data
+	Publish(event Event, build func(seq uint64) ([]byte, error)) error
+	// Subscribe starts delivering events published by any instance
+	Subscribe(deliver func(Event)) error
+}
+
+// MemoryBroker delivers events within this process only, for a single instance
+type MemoryBroker struct {
+	mu      sync.Mutex
+	seqs    map[uint]uint64
+	deliver func(Event)
+}
+
+func NewMemoryBroker() *MemoryBroker {
+	return &MemoryBroker{seqs: make(map[uint]uint64)}
+}
+
+func (b *MemoryBroker) Publish(event Event, build func(seq uint64) ([]byte, error)) error {
+	// Held while delivering so events reach the hub in sequence order
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if event.sequenced() {
+		event.Seq = b.seqs[event.GameID] + 1
+	}
+
+	if build != nil {
+		data, err := build(event.Seq)
+		if err != nil {
+			return err
+		}
+		event.Data = data
+	}
+
+	if event.sequenced() {
+		b.seqs[event.GameID] = event.Seq
+	}
+	if b.deliver != nil {
+		b.deliver(event)
+	}
+	return nil
+}
+
+func (b *MemoryBroker) Subscribe(deliver func(Event)) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.deliver = deliver
+	return nil
+}
@@ -0,0 +1,135 @@
+package websockets
+
+import (
+	"encoding/json"
+	"log"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/gofiber/contrib/websocket"
+)
+
+const (
+	// Messages a connection may have waiting before it is considered too slow,
+	// with room for a full replay on reconnect plus live traffic
+	sendQueueSize = maxLoggedEvents + 64
+	// How long a single write may block before the client is dropped
+	writeWait = 10 * time.Second
+)
+
+// outbox is the write side of a connection. Every write goes through its
+// queue and a single writer goroutine, so a stalled client only ever blocks
+// itself and never the hub or the other players
+type outbox struct {
+	messages chan []byte
+	done     chan struct{} // closed to stop the writer
+	stopped  chan struct{} // closed once the writer has returned
+	once     sync.Once
+	// Set while the connection runs a command that closes it afterwards
+	leaving atomic.Bool
+
+	closeCode   int
+	closeReason string
+}
+
+func newOutbox() *outbox {
+	return &outbox{
+		messages: make(chan []byte, sendQueueSize),
+		done:     make(chan struct{}),
+		stopped:  make(chan struct{}),
+	}
+}
+
+// enqueue queues a message without blocking. A client whose queue is full has
+// fallen too far behind and is evicted, it can reconnect and resync
+func (conn Connection) enqueue(message []byte) bool {
+	select {
+	case <-conn.out.done:
+		return false
+	default:
+	}
+
+	select {
+	case conn.out.messages <- message:
+		return true
+	default:
+		log.Printf("Evicting %s from game %d, send queue is full", conn.Username, conn.GameID)
+		conn.close(CloseTooSlow, "Connection too slow")
+		return false
+	}
+}
+
+// enqueueJSON marshals a message and queues it
+func (conn Connection) enqueueJSON(message interface{}) bool {
+	jsonMessage, err := json.Marshal(message)
+	if err != nil {
+		log.Printf("Error marshaling message: %v", err)
+		return false
+	}
+	return conn.enqueue(jsonMessage)
+}
+
+// close stops the writer, which sends the close frame. Only the first call counts
+func (conn Connection) close(code int, reason string) {
+	conn.out.once.Do(func() {
+		conn.out.closeCode = code
+		conn.out.closeReason = reason
+		close(conn.out.done)
+	})
+}
+
+// writeLoop owns all writes to the socket until the connection is closed
+func (conn Connection) writeLoop() {
+	defer close(conn.out.stopped)
+
+	ticker := time.NewTicker(pingPeriod)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			if err := conn.Conn.WriteControl(websocket.PingMessage, nil, time.Now().Add(writeWait)); err != nil {
+				conn.close(websocket.CloseAbnormalClosure, "")
+				conn.Conn.Close()
+				return
+			}
+		case message := <-conn.out.messages:
+			if err := conn.writeMessage(message); err != nil {
+				log.Printf("Error sending message to %s: %v", conn.Username, err)
+				conn.close(websocket.CloseAbnormalClosure, "")
+				// Unblocks the read loop so the connection gets cleaned up
+				conn.Conn.Close()
+				return
+			}
+		case <-conn.out.done:
+			// On a normal close, deliver what is already queued, like the ack of a leave
+			if conn.out.closeCode == websocket.CloseNormalClosure {
+				conn.flush()
+			}
+			conn.Conn.WriteControl(websocket.CloseMessage,
+				websocket.FormatCloseMessage(conn.out.closeCode, conn.out.closeReason),
+				time.Now().Add(time.Second))
+			conn.Conn.Close()
+			return
+		}
+	}
+}
+
+func (conn Connection) flush() {
+	for {
+		select {
+		case message := <-conn.out.messages:
+			if err := conn.writeMessage(message); err != nil {
+				return
+			}
+		default:
+			return
+		}
+	}
+}
+
+func (conn Connection) writeMessage(message []byte) error {
+	conn.Conn.SetWriteDeadline(time.Now().Add(writeWait))
+	return conn.Conn.WriteMessage(websocket.TextMessage, message)
+}
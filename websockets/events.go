@@ -0,0 +1,149 @@
+package websockets
+
+import (
+	"multiplayer-wordle/game"
+	"multiplayer-wordle/gamestore"
+	"multiplayer-wordle/initialisers"
+	"multiplayer-wordle/models"
+	"time"
+)
+
+// GameEvents broadcasts game engine changes to the players' sockets
+type GameEvents struct{}
+
+func (GameEvents) PlayerJoined(game models.Game) {
+	BroadcastPlayerJoined(game)
+}
+
+func (GameEvents) PlayerLeft(game models.Game, player models.Player) {
+	BroadcastPlayerLeft(game)
+	// Their open sockets would otherwise keep receiving the game's events
+	Hub.Disconnect(game.ID, player.Username)
+}
+
+func (GameEvents) GameStarted(game models.Game) {
+	BroadcastGameStarted(game)
+}
+
+func (GameEvents) NewGuess(current models.Game, guess models.Guess) {
+	// A co-op team plays one board, so every guess is shown in full
+	if current.Mode == models.GameModeCoop {
+		Hub.BroadcastToGame(current.ID, "new_guess", guess)
+		return
+	}
+	BroadcastNewGuess(current.ID, guess)
+	SendGuessToGameMaster(current, guess)
+
+	// Teammates see the word, the other team only the feedback
+	if team := current.Teams[guess.PlayerID]; game.IsTeams(current) && team != 0 {
+		Hub.BroadcastToTeam(current.ID, game.Teammates(current, team), "team_guess", guess)
+	}
+}
+
+func (GameEvents) GameOver(game models.Game, winner *models.Player, word string) {
+	BroadcastGameOver(GameOverData{
+		Game:    game,
+		Winner:  winner,
+		Word:    word,
+		Players: game.Players,
+	})
+}
+
+func (GameEvents) GameDeleted(gameID uint) {
+	Hub.ForgetGame(gameID)
+}
+
+// StateChangeData is broadcast on every move of the game state machine
+type StateChangeData struct {
+	GameID uint             `json:"gameId"`
+	From   models.GameState `json:"from"`
+	To     models.GameState `json:"to"`
+	At     time.Time        `json:"at"`
+}
+
+func (GameEvents) StateChanged(game models.Game, from, to models.GameState, at time.Time) {
+	Hub.BroadcastToGame(game.ID, "state_changed", StateChangeData{
+		GameID: game.ID,
+		From:   from,
+		To:     to,
+		At:     at,
+	})
+}
+
+func (GameEvents) Scoreboard(game models.Game, board game.Scoreboard) {
+	Hub.BroadcastToGame(game.ID, "scoreboard", board)
+}
+
+func (GameEvents) MatchOver(game models.Game, board game.Scoreboard) {
+	Hub.BroadcastToGame(game.ID, "match_over", board)
+}
+
+// DeadlineData tells the players when a race round runs out, with the
+// server's time so they can correct for their own clock
+type DeadlineData struct {
+	GameID     uint      `json:"gameId"`
+	Deadline   time.Time `json:"deadline"`
+	ServerTime time.Time `json:"serverTime"`
+	// Milliseconds left in the round
+	Remaining int64 `json:"remaining"`
+}
+
+func (GameEvents) Deadline(game models.Game, deadline time.Time) {
+	now := time.Now()
+	Hub.BroadcastToGame(game.ID, "deadline", DeadlineData{
+		GameID:     game.ID,
+		Deadline:   deadline,
+		ServerTime: now,
+		Remaining:  deadline.Sub(now).Milliseconds(),
+	})
+}
+
+func (GameEvents) RoundTick(game models.Game, deadline time.Time, left time.Duration) {
+	Hub.BroadcastToGame(game.ID, "round_tick", DeadlineData{
+		GameID:     game.ID,
+		Deadline:   deadline,
+		ServerTime: time.Now(),
+		Remaining:  left.Milliseconds(),
+	})
+}
+
+// EliminationData names the players a round knocked out of a battle royale,
+// they stay connected and watch the rest
+type EliminationData struct {
+	GameID  uint            `json:"gameId"`
+	Round   int             `json:"round"`
+	Players []models.Player `json:"players"`
+}
+
+func (GameEvents) PlayersEliminated(game models.Game, players []models.Player) {
+	Hub.BroadcastToGame(game.ID, "players_eliminated", EliminationData{
+		GameID:  game.ID,
+		Round:   game.RoundNumber,
+		Players: removePasswords(players),
+	})
+}
+
+// TeamsData is the team of each player by ID
+type TeamsData struct {
+	GameID uint         `json:"gameId"`
+	Teams  map[uint]int `json:"teams"`
+}
+
+func (GameEvents) TeamsChanged(game models.Game) {
+	Hub.BroadcastToGame(game.ID, "teams_changed", TeamsData{
+		GameID: game.ID,
+		Teams:  game.Teams,
+	})
+}
+
+// visibleTo reports whose guesses the viewer may read in the game
+func visibleTo(current models.Game, viewerID uint) func(playerID uint) bool {
+	return func(playerID uint) bool {
+		return game.CanSeeGuesses(current, viewerID, playerID)
+	}
+}
+
+// loadTeams fills in the teams of a game loaded straight from the database
+func loadTeams(current *models.Game) error {
+	return gamestore.LoadTeams(initialisers.DB, current)
+}
@@ -0,0 +1,156 @@
+package websockets
+
+import (
+	"context"
+	"encoding/json"
+	"log"
+	"multiplayer-wordle/models"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"gorm.io/gorm"
+)
+
+const (
+	// Channel every instance listens on
+	notifyChannel = "wordle_events"
+	// NOTIFY payloads must stay under 8000 bytes, larger events go through game_events
+	maxNotifyPayload = 7900
+	// Large events are kept this long for slower instances to read them
+	eventRetention = 5 * time.Minute
+)
+
+// notification is a NOTIFY payload, either the event itself or a reference
+// to a game_events row holding it
+type notification struct {
+	Event
+	Ref uint `json:"ref,omitempty"`
+}
+
+// PostgresBroker shares events between instances with LISTEN/NOTIFY on the
+// database the app already uses. Sequence numbers come from the
+// game_event_sequences table, and NOTIFY is sent in the same transaction
+// that takes the number, so every instance receives a game's events in order
+type PostgresBroker struct {
+	db  *gorm.DB
+	dsn string
+}
+
+func NewPostgresBroker(db *gorm.DB, dsn string) *PostgresBroker {
+	return &PostgresBroker{db: db, dsn: dsn}
+}
+
+func (b *PostgresBroker) Publish(event Event, build func(seq uint64) ([]byte, error)) error {
+	return b.db.Transaction(func(tx *gorm.DB) error {
+		if event.sequenced() {
+			// The row lock orders concurrent publishers in the same game
+			if err := tx.Raw(`INSERT INTO game_event_sequences (game_id, seq) VALUES (?, 1)
+				ON CONFLICT (game_id) DO UPDATE SET seq = game_event_sequences.seq + 1
+				RETURNING seq`, event.GameID).Scan(&event.Seq).Error; err != nil {
+				return err
+			}
+		}
+
+		if build != nil {
+			data, err := build(event.Seq)
+			if err != nil {
+				return err
+			}
+			event.Data = data
+		}
+
+		payload, err := json.Marshal(notification{Event: event})
+		if err != nil {
+			return err
+		}
+
+		if len(payload) > maxNotifyPayload {
+			event := models.GameEvent{Payload: string(payload)}
+			if err := tx.Create(&event).Error; err != nil {
+				return err
+			}
+			tx.Where("created_at < ?", time.Now().Add(-eventRetention)).Delete(&models.GameEvent{})
+
+			if payload, err = json.Marshal(notification{Ref: event.ID}); err != nil {
+				return err
+			}
+		}
+
+		return tx.Exec("SELECT pg_notify(?, ?)", notifyChannel, string(payload)).Error
+	})
+}
+
+func (b *PostgresBroker) Subscribe(deliver func(Event)) error {
+	conn, err := b.listen()
+	if err != nil {
+		return err
+	}
+	go b.run(conn, deliver)
+	return nil
+}
+
+func (b *PostgresBroker) listen() (*pgx.Conn, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	conn, err := pgx.Connect(ctx, b.dsn)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := conn.Exec(ctx, "LISTEN "+notifyChannel); err != nil {
+		conn.Close(ctx)
+		return nil, err
+	}
+	return conn, nil
+}
+
+// run receives notifications until the connection drops, then reconnects.
+// Events sent while disconnected are lost here, clients notice the gap in
+// sequence numbers and get a snapshot when they resync
+func (b *PostgresBroker) run(conn *pgx.Conn, deliver func(Event)) {
+	for {
+		notice, err := conn.WaitForNotification(context.Background())
+		if err != nil {
+			log.Printf("Lost the %s listener: %v", notifyChannel, err)
+			conn.Close(context.Background())
+			conn = b.reconnect()
+			continue
+		}
+
+		var message notification
+		if err := json.Unmarshal([]byte(notice.Payload), &message); err != nil {
+			log.Printf("Invalid notification payload: %v", err)
+			continue
+		}
+
+		if message.Ref != 0 {
+			var event models.GameEvent
+			if err := b.db.First(&event, message.Ref).Error; err != nil {
+				log.Printf("Error loading game event %d: %v", message.Ref, err)
+				continue
+			}
+			if err := json.Unmarshal([]byte(event.Payload), &message); err != nil {
+				log.Printf("Invalid game event %d: %v", message.Ref, err)
+				continue
+			}
+		}
+
+		deliver(message.Event)
+	}
+}
+
+func (b *PostgresBroker) reconnect() *pgx.Conn {
+	delay := time.Second
+	for {
+		conn, err := b.listen()
+		if err == nil {
+			log.Printf("Listening on %s again", notifyChannel)
+			return conn
+		}
+		log.Printf("Error reconnecting the %s listener: %v", notifyChannel, err)
+		time.Sleep(delay)
+		if delay < 30*time.Second {
+			delay *= 2
+		}
+	}
+}
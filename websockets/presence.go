@@ -0,0 +1,255 @@
+package websockets
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"log"
+	"multiplayer-wordle/initialisers"
+	"multiplayer-wordle/models"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+const (
+	// A client that sends nothing, not even a pong, for this long is dropped
+	pongWait = 60 * time.Second
+	// Pings go out a bit more often than pongWait so a healthy client never times out
+	pingPeriod = (pongWait * 9) / 10
+	// Largest message a client may send
+	maxMessageSize = 4096
+
+	// How often an instance renews the presence of the players connected to it
+	presenceHeartbeat = 15 * time.Second
+	// Presence not renewed for this long is left over from an instance that stopped
+	presenceTTL = 3 * presenceHeartbeat
+)
+
+// instanceID tells this server's presence rows apart from other instances'
+var instanceID = newInstanceID()
+
+func newInstanceID() string {
+	id := make([]byte, 8)
+	if _, err := rand.Read(id); err != nil {
+		return strconv.FormatInt(time.Now().UnixNano(), 36)
+	}
+	return hex.EncodeToString(id)
+}
+
+// PresenceData is broadcast whenever a player comes online or goes offline
+type PresenceData struct {
+	Username string   `json:"username"`
+	Online   bool     `json:"online"`
+	Players  []string `json:"players"` // Everyone currently online in the game
+}
+
+// PresenceHandler is notified after a player's presence in a game changes,
+// when they connect through the first instance or drop off the last one
+type PresenceHandler func(gameID uint, username string, online bool)
+
+var (
+	presenceMu       sync.RWMutex
+	presenceHandlers []PresenceHandler
+)
+
+// OnPresenceChange registers a handler for presence changes, game logic that
+// depends on who is connected lives in controllers
+func OnPresenceChange(handler PresenceHandler) {
+	presenceMu.Lock()
+	defer presenceMu.Unlock()
+	presenceHandlers = append(presenceHandlers, handler)
+}
+
+// IsOnline reports whether a player has an open connection to a game on any
+// server instance
+func (h *GameHub) IsOnline(gameID uint, username string) bool {
+	var count int64
+	if err := livePresences(gameID).Where("username = ?", username).Count(&count).Error; err != nil {
+		log.Printf("Error checking whether %s is online in game %d: %v", username, gameID, err)
+		h.mu.RLock()
+		defer h.mu.RUnlock()
+		return h.connectionCount(gameID, username) > 0
+	}
+	return count > 0
+}
+
+// OnlinePlayers returns the usernames with an open connection to a game on
+// any server instance
+func (h *GameHub) OnlinePlayers(gameID uint) []string {
+	players := []string{}
+	if err := livePresences(gameID).Distinct("username").Order("username").Pluck("username", &players).Error; err != nil {
+		log.Printf("Error fetching who is online in game %d: %v", gameID, err)
+		return h.localPlayers(gameID)
+	}
+	return players
+}
+
+// localPlayers returns the usernames connected to a game through this instance
+func (h *GameHub) localPlayers(gameID uint) []string {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+
+	seen := make(map[string]bool)
+	players := []string{}
+	for _, conn := range h.connections[gameID] {
+		if !seen[conn.Username] {
+			seen[conn.Username] = true
+			players = append(players, conn.Username)
+		}
+	}
+	sort.Strings(players)
+	return players
+}
+
+// connectionCount counts a player's connections, the hub lock must be held
+func (h *GameHub) connectionCount(gameID uint, username string) int {
+	count := 0
+	for _, conn := range h.connections[gameID] {
+		if conn.Username == username {
+			count++
+		}
+	}
+	return count
+}
+
+// joined shares that this instance holds a connection of the player, and
+// announces them unless another instance already did
+func (h *GameHub) joined(gameID uint, username string) {
+	db := initialisers.DB
+
+	err := db.Clauses(clause.OnConflict{UpdateAll: true}).Create(&models.PlayerPresence{
+		InstanceID: instanceID,
+		GameID:     gameID,
+		Username:   username,
+		SeenAt:     time.Now(),
+	}).Error
+	if err != nil {
+		log.Printf("Error recording that %s is online in game %d: %v", username, gameID, err)
+	}
+
+	var elsewhere int64
+	if err := livePresences(gameID).Where("username = ? AND instance_id <> ?", username, instanceID).Count(&elsewhere).Error; err != nil {
+		log.Printf("Error checking whether %s is online in game %d: %v", username, gameID, err)
+	}
+	if elsewhere == 0 {
+		h.presenceChanged(gameID, username, true)
+	}
+}
+
+// left withdraws this instance's presence of a player whose last connection
+// here closed, and announces them gone unless another instance still has them
+func (h *GameHub) left(gameID uint, username string) {
+	err := initialisers.DB.
+		Where("instance_id = ? AND game_id = ? AND username = ?", instanceID, gameID, username).
+		Delete(&models.PlayerPresence{}).Error
+	if err != nil {
+		log.Printf("Error recording that %s went offline in game %d: %v", username, gameID, err)
+	}
+
+	// They reconnected here in the meantime, the next heartbeat restores the row
+	h.mu.RLock()
+	reconnected := h.connectionCount(gameID, username) > 0
+	h.mu.RUnlock()
+
+	if !reconnected && !h.IsOnline(gameID, username) {
+		h.presenceChanged(gameID, username, false)
+	}
+}
+
+// StartPresence keeps this instance's presence rows fresh and notices the
+// players of instances that went away without closing their connections
+func (h *GameHub) StartPresence() {
+	go func() {
+		ticker := time.NewTicker(presenceHeartbeat)
+		defer ticker.Stop()
+		for {
+			h.refreshPresence()
+			h.expirePresence()
+			<-ticker.C
+		}
+	}()
+}
+
+// refreshPresence renews the row of every player connected to this instance
+func (h *GameHub) refreshPresence() {
+	now := time.Now()
+
+	h.mu.RLock()
+	var presences []models.PlayerPresence
+	seen := make(map[string]bool)
+	for gameID, conns := range h.connections {
+		for _, conn := range conns {
+			key := fmt.Sprintf("%d:%s", gameID, conn.Username)
+			if seen[key] {
+				continue
+			}
+			seen[key] = true
+			presences = append(presences, models.PlayerPresence{
+				InstanceID: instanceID,
+				GameID:     gameID,
+				Username:   conn.Username,
+				SeenAt:     now,
+			})
+		}
+	}
+	h.mu.RUnlock()
+
+	if len(presences) == 0 {
+		return
+	}
+	if err := initialisers.DB.Clauses(clause.OnConflict{UpdateAll: true}).Create(&presences).Error; err != nil {
+		log.Println("Error refreshing presence:", err)
+	}
+}
+
+// expirePresence drops the rows nobody refreshed for too long. Their players
+// went offline when their instance stopped, unless they are online elsewhere
+func (h *GameHub) expirePresence() {
+	var expired []models.PlayerPresence
+	err := initialisers.DB.Clauses(clause.Returning{}).
+		Where("seen_at < ?", time.Now().Add(-presenceTTL)).
+		Delete(&expired).Error
+	if err != nil {
+		log.Println("Error expiring presence:", err)
+		return
+	}
+
+	gone := make(map[string]bool)
+	for _, presence := range expired {
+		key := fmt.Sprintf("%d:%s", presence.GameID, presence.Username)
+		if gone[key] || h.IsOnline(presence.GameID, presence.Username) {
+			continue
+		}
+		gone[key] = true
+		h.presenceChanged(presence.GameID, presence.Username, false)
+	}
+}
+
+// livePresences queries the fresh presence rows of a game
+func livePresences(gameID uint) *gorm.DB {
+	return initialisers.DB.Model(&models.PlayerPresence{}).
+		Where("game_id = ? AND seen_at > ?", gameID, time.Now().Add(-presenceTTL))
+}
+
+// presenceChanged tells the game and the registered handlers about a player
+// coming online or going offline
+func (h *GameHub) presenceChanged(gameID uint, username string, online bool) {
+	h.BroadcastToGame(gameID, "presence_changed", PresenceData{
+		Username: username,
+		Online:   online,
+		Players:  h.OnlinePlayers(gameID),
+	})
+
+	presenceMu.RLock()
+	handlers := append([]PresenceHandler(nil), presenceHandlers...)
+	presenceMu.RUnlock()
+
+	for _, handler := range handlers {
+		handler(gameID, username, online)
+	}
+}
@@ -0,0 +1,190 @@
+package websockets
+
+import (
+	"encoding/json"
+	"errors"
+	"log"
+	"multiplayer-wordle/models"
+	"sync"
+	"time"
+)
+
+// ClientMessage is a request sent by a client over the socket. The ID is
+// echoed back in the ack or error reply so the client can match them up
+type ClientMessage struct {
+	ID      string          `json:"id"`
+	Type    string          `json:"type"`
+	Payload json.RawMessage `json:"payload"`
+}
+
+// Reply answers a single ClientMessage
+type Reply struct {
+	Type    string      `json:"type"`
+	ID      string      `json:"id,omitempty"`
+	Payload interface{} `json:"payload"`
+}
+
+// CommandError is an error that carries its own reply body, for example the
+// same body the REST handler would have returned
+type CommandError interface {
+	error
+	Payload() interface{}
+}
+
+// CommandHandler runs a client command and returns the ack payload
+type CommandHandler func(conn Connection, payload json.RawMessage) (interface{}, error)
+
+// ErrInvalidPayload is returned when a command payload can't be decoded
+var ErrInvalidPayload = errors.New("Invalid message payload")
+
+var (
+	commandsMu sync.RWMutex
+	commands   = map[string]CommandHandler{}
+
+	// Commands after which the server closes the socket once the ack is sent
+	closingCommands = map[string]bool{"leave": true}
+)
+
+// RegisterCommand adds a handler for a client message type. The game actions
+// live in controllers, which registers them at startup
+func RegisterCommand(name string, handler CommandHandler) {
+	commandsMu.Lock()
+	defer commandsMu.Unlock()
+	commands[name] = handler
+}
+
+func init() {
+	RegisterCommand("ping", handlePing)
+	RegisterCommand("ready", handleReady)
+}
+
+// handleMessage dispatches a client message and replies with an ack or an
+// error. It reports whether the connection should be closed afterwards
+func (h *GameHub) handleMessage(conn Connection, data []byte) bool {
+	var message ClientMessage
+	if err := json.Unmarshal(data, &message); err != nil {
+		h.reply(conn, Reply{Type: "error", Payload: map[string]string{"error": "Invalid message format"}})
+		return false
+	}
+
+	commandsMu.RLock()
+	handler, ok := commands[message.Type]
+	commandsMu.RUnlock()
+	if !ok {
+		h.reply(conn, Reply{Type: "error", ID: message.ID, Payload: map[string]string{"error": "Unknown message type: " + message.Type}})
+		return false
+	}
+
+	closing := closingCommands[message.Type]
+	conn.out.leaving.Store(closing)
+	result, err := handler(conn, message.Payload)
+	if err != nil {
+		conn.out.leaving.Store(false)
+		var payload interface{} = map[string]string{"error": err.Error()}
+		if cmdErr, ok := err.(CommandError); ok {
+			payload = cmdErr.Payload()
+		}
+		h.reply(conn, Reply{Type: "error", ID: message.ID, Payload: payload})
+		return false
+	}
+
+	h.reply(conn, Reply{Type: "ack", ID: message.ID, Payload: result})
+	return closing
+}
+
+func (h *GameHub) reply(conn Connection, reply Reply) {
+	if !conn.enqueueJSON(reply) {
+		log.Printf("Could not reply to %s", conn.Username)
+	}
+}
+
+func handlePing(conn Connection, payload json.RawMessage) (interface{}, error) {
+	return map[string]interface{}{"pong": time.Now().UnixMilli()}, nil
+}
+
+// ReadyInput toggles whether a player is ready for the next round
+type ReadyInput struct {
+	Ready *bool `json:"ready"`
+}
+
+// readyPlayers tracks who has marked themselves ready in each lobby
+var readyPlayers = struct {
+	sync.Mutex
+	games map[uint]map[string]bool
+}{games: make(map[uint]map[string]bool)}
+
+func handleReady(conn Connection, payload json.RawMessage) (interface{}, error) {
+	input := ReadyInput{}
+	if len(payload) > 0 {
+		if err := json.Unmarshal(payload, &input); err != nil {
+			return nil, ErrInvalidPayload
+		}
+	}
+	ready := input.Ready == nil || *input.Ready
+
+	readyPlayers.Lock()
+	players := readyPlayers.games[conn.GameID]
+	if players == nil {
+		players = make(map[string]bool)
+		readyPlayers.games[conn.GameID] = players
+	}
+	if ready {
+		players[conn.Username] = true
+	} else {
+		delete(players, conn.Username)
+	}
+	snapshot := readyList(players)
+	readyPlayers.Unlock()
+
+	Hub.BroadcastToGame(conn.GameID, "player_ready", map[string]interface{}{
+		"username": conn.Username,
+		"ready":    ready,
+		"players":  snapshot,
+	})
+	return map[string]bool{"ready": ready}, nil
+}
+
+// ReadyPlayers returns the usernames that are ready in a game
+func ReadyPlayers(gameID uint) []string {
+	readyPlayers.Lock()
+	defer readyPlayers.Unlock()
+	return readyList(readyPlayers.games[gameID])
+}
+
+// ClearReady forgets ready flags, for the whole game when no usernames are given
+func ClearReady(gameID uint, usernames ...string) {
+	readyPlayers.Lock()
+	defer readyPlayers.Unlock()
+
+	if len(usernames) == 0 {
+		delete(readyPlayers.games, gameID)
+		return
+	}
+	for _, username := range usernames {
+		delete(readyPlayers.games[gameID], username)
+	}
+}
+
+// pruneReady drops ready flags of players that are no longer in the game
+func pruneReady(gameID uint, players []models.Player) {
+	inGame := make(map[string]bool, len(players))
+	for _, player := range players {
+		inGame[player.Username] = true
+	}
+
+	readyPlayers.Lock()
+	defer readyPlayers.Unlock()
+	for username := range readyPlayers.games[gameID] {
+		if !inGame[username] {
+			delete(readyPlayers.games[gameID], username)
+		}
+	}
+}
+
+func readyList(players map[string]bool) []string {
+	list := make([]string, 0, len(players))
+	for username := range players {
+		list = append(list, username)
+	}
+	return list
+}
@@ -0,0 +1,195 @@
+package websockets
+
+import (
+	"encoding/json"
+	"log"
+	"multiplayer-wordle/initialisers"
+	"multiplayer-wordle/models"
+)
+
+// How many broadcast events each game keeps for replay. A client that missed
+// more than this gets a snapshot instead
+const maxLoggedEvents = 256
+
+// loggedEvent is a broadcast already marshaled with its sequence number
+type loggedEvent struct {
+	Seq  uint64
+	Data []byte
+	// Only these players get it replayed, everyone when empty
+	Recipients []string
+}
+
+// eventLog is the sequenced history of broadcasts in one game
+type eventLog struct {
+	seq    uint64
+	events []loggedEvent
+}
+
+// append records an event. A jump in sequence numbers, from a listener that
+// reconnected or an instance that started mid-game, drops the older history
+// so the log stays contiguous and clients behind the jump get a snapshot
+func (l *eventLog) append(seq uint64, data []byte, recipients []string) {
+	if seq != l.seq+1 {
+		l.events = nil
+	}
+	l.seq = seq
+	l.events = append(l.events, loggedEvent{Seq: seq, Data: data, Recipients: recipients})
+	if len(l.events) > maxLoggedEvents {
+		l.events = l.events[len(l.events)-maxLoggedEvents:]
+	}
+}
+
+// since returns the events after seq, and false if some of them are no longer
+// kept. A seq ahead of the log means the server restarted since the client
+// last saw an event, so replaying can't help either
+func (l *eventLog) since(seq uint64) ([]loggedEvent, bool) {
+	if seq == l.seq {
+		return nil, true
+	}
+	if seq > l.seq || len(l.events) == 0 || l.events[0].Seq > seq+1 {
+		return nil, false
+	}
+
+	start := len(l.events) - int(l.seq-seq)
+	missed := make([]loggedEvent, len(l.events)-start)
+	copy(missed, l.events[start:])
+	return missed, true
+}
+
+// Snapshot is the full state sent to a client that is too far behind to replay
+type Snapshot struct {
+	Seq    uint64      `json:"seq"`
+	Game   models.Game `json:"game"`
+	Ready  []string    `json:"ready"`
+	Online []string    `json:"online"`
+}
+
+// ResyncInput asks for everything after the last sequence number the client saw
+type ResyncInput struct {
+	Since uint64 `json:"since"`
+}
+
+func init() {
+	RegisterCommand("resync", handleResync)
+}
+
+func handleResync(conn Connection, payload json.RawMessage) (interface{}, error) {
+	input := ResyncInput{}
+	if len(payload) > 0 {
+		if err := json.Unmarshal(payload, &input); err != nil {
+			return nil, ErrInvalidPayload
+		}
+	}
+	seq, _ := Hub.resync(conn, &input.Since, false)
+	return map[string]uint64{"seq": seq}, nil
+}
+
+// resync queues the events a connection missed after since, or a snapshot
+// when since is nil or the gap is no longer in the log. With register set the
+// connection is added to the hub under the same lock, so no live event can
+// slip in between the replay and the broadcasts that follow it. It returns
+// the sequence number the client is now caught up to, and whether registering
+// brought the player online
+func (h *GameHub) resync(conn Connection, since *uint64, register bool) (uint64, bool) {
+	h.mu.Lock()
+	var missed []loggedEvent
+	complete := false
+	if since != nil {
+		missed, complete = h.logFor(conn.GameID).since(*since)
+	}
+
+	var snapshot []byte
+	if !complete {
+		// The snapshot is read from the database without holding the hub, then
+		// followed by every event logged since it was taken. Some of those may
+		// already be part of the snapshot, clients should treat them as idempotent
+		base := h.logFor(conn.GameID).seq
+		h.mu.Unlock()
+
+		data, err := snapshotMessage(conn, base)
+		if err != nil {
+			log.Printf("Error building snapshot for %s in game %d: %v", conn.Username, conn.GameID, err)
+		}
+		snapshot = data
+
+		h.mu.Lock()
+		missed, _ = h.logFor(conn.GameID).since(base)
+	}
+	defer h.mu.Unlock()
+
+	cameOnline := false
+	if register {
+		cameOnline = h.connectionCount(conn.GameID, conn.Username) == 0
+		h.connections[conn.GameID] = append(h.connections[conn.GameID], conn)
+	}
+	if snapshot != nil {
+		conn.enqueue(snapshot)
+	}
+	for _, event := range missed {
+		if isRecipient(event.Recipients, conn.Username) {
+			conn.enqueue(event.Data)
+		}
+	}
+	return h.logFor(conn.GameID).seq, cameOnline
+}
+
+func snapshotMessage(conn Connection, seq uint64) ([]byte, error) {
+	snapshot, err := buildSnapshot(conn, seq)
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(Message{Type: "snapshot", Seq: seq, Payload: snapshot})
+}
+
+// logFor returns the event log of a game, the hub lock must be held
+func (h *GameHub) logFor(gameID uint) *eventLog {
+	events, ok := h.logs[gameID]
+	if !ok {
+		events = &eventLog{}
+		h.logs[gameID] = events
+	}
+	return events
+}
+
+// ForgetGame drops the event log of a deleted game
+func (h *GameHub) ForgetGame(gameID uint) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	delete(h.logs, gameID)
+	ClearReady(gameID)
+}
+
+// buildSnapshot loads the game as the connected player is allowed to see it
+func buildSnapshot(conn Connection, seq uint64) (Snapshot, error) {
+	db := initialisers.DB
+
+	var player models.Player
+	if err := db.Where("username = ?", conn.Username).First(&player).Error; err != nil {
+		return Snapshot{}, err
+	}
+
+	var game models.Game
+	if err := db.Where("id = ?", conn.GameID).Preload("Players").Preload("Guesses").First(&game).Error; err != nil {
+		return Snapshot{}, err
+	}
+
+	if err := loadTeams(&game); err != nil {
+		return Snapshot{}, err
+	}
+
+	// The game master already knows the word and sees every guess, everyone
+	// else sees their own and those of their co-op board or team
+	isGameMaster := game.GameMasterID != nil && *game.GameMasterID == player.ID
+	if isGameMaster {
+		removePasswords(game.Players)
+	} else {
+		game = maskGameInfo(game, visibleTo(game, player.ID))
+	}
+
+	return Snapshot{
+		Seq:    seq,
+		Game:   game,
+		Ready:  ReadyPlayers(game.ID),
+		Online: Hub.OnlinePlayers(game.ID),
+	}, nil
+}
@@ -5,8 +5,12 @@ import (
 	"fmt"
 	"log"
 	"multiplayer-wordle/initialisers"
+	"multiplayer-wordle/middlewares"
 	"multiplayer-wordle/models"
+	"strconv"
+	"strings"
 	"sync"
+	"time"
 
 	"github.com/gofiber/contrib/websocket"
 )
@@ -15,7 +19,11 @@ import (
 type GameHub struct {
 	// connections stores active WebSocket connections per game
 	connections map[uint][]Connection
-	mu          sync.RWMutex
+	// logs keeps the recent broadcasts of each game for reconnecting clients
+	logs map[uint]*eventLog
+	// broker carries broadcasts to every server instance, including this one
+	broker Broker
+	mu     sync.RWMutex
 }
 
 // Connection represents a WebSocket connection for a specific player
@@ -23,39 +31,105 @@ type Connection struct {
 	Conn     *websocket.Conn
 	GameID   uint
 	Username string
+	out      *outbox
 }
 
 // Message represents the structure of WebSocket messages
 type Message struct {
 	Type    string      `json:"type"`
+	Seq     uint64      `json:"seq,omitempty"` // Set on broadcasts, increases by one per event in a game
 	Payload interface{} `json:"payload"`
 }
 
 var (
 	// Hub is the global instance of GameHub
-	Hub = &GameHub{
+	Hub = newGameHub()
+)
+
+// newGameHub creates a hub that only reaches connections in this process
+func newGameHub() *GameHub {
+	h := &GameHub{
 		connections: make(map[uint][]Connection),
+		logs:        make(map[uint]*eventLog),
 	}
+	h.UseBroker(NewMemoryBroker())
+	return h
+}
+
+// Close codes sent when a connection is rejected
+const (
+	CloseUnauthorized = 4001
+	CloseForbidden    = 4003
+	CloseGameNotFound = 4004
+	// Sent when a client is evicted for not keeping up, it may reconnect
+	CloseTooSlow = 4008
 )
 
+// Subprotocol a client offers alongside its JWT, browsers can't set headers on a WebSocket
+const TokenSubprotocol = "bearer"
+
+// tokenFromRequest reads the JWT from the token query param or from the
+// subprotocols, sent as ["bearer", "<jwt>"]
+func tokenFromRequest(c *websocket.Conn) string {
+	if token := c.Query("token"); token != "" {
+		return token
+	}
+
+	header := c.Headers("Sec-Websocket-Protocol")
+	if header == "" {
+		header = c.Headers("Sec-WebSocket-Protocol")
+	}
+
+	protocols := strings.Split(header, ",")
+	for i, protocol := range protocols {
+		if strings.TrimSpace(protocol) == TokenSubprotocol && i+1 < len(protocols) {
+			return strings.TrimSpace(protocols[i+1])
+		}
+	}
+	return ""
+}
+
+// rejectConnection closes the socket with a close code the client can act on
+func rejectConnection(c *websocket.Conn, code int, reason string) {
+	log.Printf("Rejecting WebSocket connection: %s", reason)
+	c.WriteControl(websocket.CloseMessage, websocket.FormatCloseMessage(code, reason), time.Now().Add(time.Second))
+	c.Close()
+}
+
 // HandleConnection manages a new WebSocket connection
 func (h *GameHub) HandleConnection(c *websocket.Conn) {
-	// Get gameID and username from query params
+	db := initialisers.DB
 
-	gameID := c.Params("gameID")
-	username := c.Query("username")
+	// The username comes from the JWT, never from the client
+	username, err := middlewares.ParseToken(tokenFromRequest(c))
+	if err != nil {
+		rejectConnection(c, CloseUnauthorized, "Invalid or expired token")
+		return
+	}
 
-	if gameID == "" || username == "" {
-		log.Printf("Missing required parameters: gameID=%s, username=%s", gameID, username)
-		c.Close()
+	gameID := c.Params("gameID")
+	if gameID == "" {
+		rejectConnection(c, CloseGameNotFound, "Missing game ID")
 		return
 	}
 
-	// Convert gameID to uint
 	var game models.Game
-	if err := initialisers.DB.Where("id = ?", gameID).First(&game).Error; err != nil {
+	if err := db.Where("id = ?", gameID).First(&game).Error; err != nil {
 		log.Printf("Error fetching game: %v\n", err)
-		c.Close()
+		rejectConnection(c, CloseGameNotFound, "Game not found")
+		return
+	}
+
+	var player models.Player
+	if err := db.Where("username = ?", username).First(&player).Error; err != nil {
+		rejectConnection(c, CloseUnauthorized, "Player not found")
+		return
+	}
+
+	// Only players in the game may listen to its events
+	var membership int64
+	if err := db.Table("game_players").Where("game_id = ? AND player_id = ?", game.ID, player.ID).Count(&membership).Error; err != nil || membership == 0 {
+		rejectConnection(c, CloseForbidden, "You are not in this game")
 		return
 	}
 
@@ -64,20 +138,43 @@ func (h *GameHub) HandleConnection(c *websocket.Conn) {
 		Conn:     c,
 		GameID:   game.ID,
 		Username: username,
+		out:      newOutbox(),
+	}
+	go conn.writeLoop()
+
+	// Add connection to hub, then catch the client up. A reconnecting client
+	// passes the last sequence number it saw and only gets what it missed
+	var since *uint64
+	if value := c.Query("since"); value != "" {
+		if parsed, err := strconv.ParseUint(value, 10, 64); err == nil {
+			since = &parsed
+		}
+	}
+	if _, cameOnline := h.resync(conn, since, true); cameOnline {
+		h.joined(conn.GameID, conn.Username)
 	}
 
-	// Add connection to hub
-	h.addConnection(conn)
-
-	// Remove connection when function returns
+	// Remove connection when function returns. The writer must be done before
+	// the handler returns, the socket is recycled afterwards
 	defer func() {
-		h.removeConnection(conn)
+		if wentOffline := h.removeConnection(conn); wentOffline {
+			h.left(conn.GameID, conn.Username)
+		}
+		conn.close(websocket.CloseNormalClosure, "")
+		<-conn.out.stopped
 		conn.Conn.Close()
 	}()
 
+	// Any message or pong keeps the connection alive, the writer sends the pings
+	c.SetReadLimit(maxMessageSize)
+	c.SetReadDeadline(time.Now().Add(pongWait))
+	c.SetPongHandler(func(string) error {
+		return c.SetReadDeadline(time.Now().Add(pongWait))
+	})
+
 	// Listen for WebSocket messages
 	for {
-		messageType, _, err := c.ReadMessage()
+		messageType, data, err := c.ReadMessage()
 		if err != nil {
 			if websocket.IsUnexpectedCloseError(err, websocket.CloseGoingAway, websocket.CloseAbnormalClosure) {
 				log.Printf("Unexpected close error: %v", err)
@@ -88,25 +185,24 @@ func (h *GameHub) HandleConnection(c *websocket.Conn) {
 			log.Printf("Received close message from %s in game %d", conn.Username, conn.GameID)
 			break
 		}
+		c.SetReadDeadline(time.Now().Add(pongWait))
+		if messageType == websocket.TextMessage {
+			if closeAfter := h.handleMessage(conn, data); closeAfter {
+				break
+			}
+		}
 	}
 }
 
-// addConnection adds a new connection to the hub
-func (h *GameHub) addConnection(conn Connection) {
-	h.mu.Lock()
-	defer h.mu.Unlock()
-
-	h.connections[conn.GameID] = append(h.connections[conn.GameID], conn)
-}
-
-// removeConnection removes a connection from the hub
-func (h *GameHub) removeConnection(conn Connection) {
+// removeConnection removes a connection from the hub and reports whether it
+// was the player's last one in the game
+func (h *GameHub) removeConnection(conn Connection) bool {
 	h.mu.Lock()
 	defer h.mu.Unlock()
 
 	conns := h.connections[conn.GameID]
 	for i, c := range conns {
-		if c.Conn == conn.Conn {
+		if c.out == conn.out {
 			h.connections[conn.GameID] = append(conns[:i], conns[i+1:]...)
 			break
 		}
@@ -116,32 +212,102 @@ func (h *GameHub) removeConnection(conn Connection) {
 	if len(h.connections[conn.GameID]) == 0 {
 		delete(h.connections, conn.GameID)
 	}
+
+	return h.connectionCount(conn.GameID, conn.Username) == 0
 }
 
-// BroadcastToGame sends a message to all connected clients in a specific game
+// BroadcastToGame sends a message to all connected clients in a specific game,
+// on every server instance
 func (h *GameHub) BroadcastToGame(gameID uint, messageType string, payload interface{}) {
-	message := Message{
-		Type:    messageType,
-		Payload: payload,
+	err := h.broker.Publish(Event{GameID: gameID}, func(seq uint64) ([]byte, error) {
+		return json.Marshal(Message{
+			Type:    messageType,
+			Seq:     seq,
+			Payload: payload,
+		})
+	})
+	if err != nil {
+		fmt.Printf("Error broadcasting %s to game %d: %v\n", messageType, gameID, err)
+	}
+}
+
+// SendToPlayer sends a message only to the connections of one player in a game
+func (h *GameHub) SendToPlayer(gameID uint, username string, messageType string, payload interface{}) {
+	err := h.broker.Publish(Event{GameID: gameID, Username: username}, func(uint64) ([]byte, error) {
+		return json.Marshal(Message{
+			Type:    messageType,
+			Payload: payload,
+		})
+	})
+	if err != nil {
+		fmt.Printf("Error sending %s to %s: %v\n", messageType, username, err)
 	}
+}
 
-	jsonMessage, err := json.Marshal(message)
+// Disconnect closes a player's connections to a game on every server
+// instance, once they are no longer in it
+func (h *GameHub) Disconnect(gameID uint, username string) {
+	err := h.broker.Publish(Event{GameID: gameID, Username: username, Disconnect: true}, nil)
 	if err != nil {
-		fmt.Printf("Error marshaling message: %v\n", err)
+		fmt.Printf("Error disconnecting %s from game %d: %v\n", username, gameID, err)
+	}
+}
+
+// BroadcastToTeam sends a message to the connections of the players on one
+// team of a game, the rest of the game doesn't see it. Unlike SendToPlayer it
+// is sequenced, so a teammate who reconnects gets it replayed
+func (h *GameHub) BroadcastToTeam(gameID uint, members []models.Player, messageType string, payload interface{}) {
+	if len(members) == 0 {
 		return
 	}
+	recipients := make([]string, len(members))
+	for i, member := range members {
+		recipients[i] = member.Username
+	}
 
-	h.mu.RLock()
-	defer h.mu.RUnlock()
+	err := h.broker.Publish(Event{GameID: gameID, Recipients: recipients}, func(seq uint64) ([]byte, error) {
+		return json.Marshal(Message{
+			Type:    messageType,
+			Seq:     seq,
+			Payload: payload,
+		})
+	})
+	if err != nil {
+		fmt.Printf("Error broadcasting %s to a team in game %d: %v\n", messageType, gameID, err)
+	}
+}
 
-	for _, conn := range h.connections[gameID] {
-		err := conn.Conn.WriteMessage(websocket.TextMessage, jsonMessage)
-		if err != nil {
-			fmt.Printf("Error sending message to %s: %v\n", conn.Username, err)
+// deliver hands an event from the broker to this instance's connections.
+// Broadcasts are logged under the same lock so replays line up with live events
+func (h *GameHub) deliver(event Event) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if event.Seq != 0 {
+		h.logFor(event.GameID).append(event.Seq, event.Data, event.Recipients)
+	}
+
+	for _, conn := range h.connections[event.GameID] {
+		if !event.reaches(conn.Username) {
+			continue
 		}
+		if event.Disconnect {
+			// A connection that sent the leave closes itself once it is acked
+			if !conn.out.leaving.Load() {
+				conn.close(websocket.CloseNormalClosure, "You are no longer in this game")
+			}
+			continue
+		}
+		conn.enqueue(event.Data)
 	}
 }
 
+// UseBroker switches how events travel between instances, before the server starts
+func (h *GameHub) UseBroker(broker Broker) error {
+	h.broker = broker
+	return broker.Subscribe(h.deliver)
+}
+
 type GameOverData struct {
 	Game    models.Game
 	Winner  *models.Player
@@ -149,10 +315,15 @@ type GameOverData struct {
 	Players []models.Player
 }
 
-// Masking password and guess word
-func maskGameInfo(game models.Game) models.Game {
+// Masking password and guess word. Guesses stay readable where visible says
+// so, a nil visible masks them all
+func maskGameInfo(game models.Game, visible func(playerID uint) bool) models.Game {
 	removePasswords(game.Players)
-	removeGuesses(game.Guesses)
+	for i := range game.Guesses {
+		if visible == nil || !visible(game.Guesses[i].PlayerID) {
+			game.Guesses[i].GuessWord = ""
+		}
+	}
 	game.Word = ""
 	return game
 }
@@ -173,22 +344,24 @@ func removeGuesses(guesses []models.Guess) []models.Guess {
 
 // Convenience functions for broadcasting specific game events
 func BroadcastGameCreated(game models.Game) {
-	maskedGame := maskGameInfo(game)
+	maskedGame := maskGameInfo(game, nil)
 	Hub.BroadcastToGame(game.ID, "game_created", maskedGame)
 }
 
 func BroadcastPlayerJoined(game models.Game) {
-	maskedGame := maskGameInfo(game)
+	maskedGame := maskGameInfo(game, nil)
 	Hub.BroadcastToGame(game.ID, "player_joined", maskedGame)
 }
 
 func BroadcastPlayerLeft(game models.Game) {
-	maskedGame := maskGameInfo(game)
+	pruneReady(game.ID, game.Players)
+	maskedGame := maskGameInfo(game, nil)
 	Hub.BroadcastToGame(game.ID, "player_left", maskedGame)
 }
 
 func BroadcastGameStarted(game models.Game) {
-	maskedGame := maskGameInfo(game)
+	ClearReady(game.ID)
+	maskedGame := maskGameInfo(game, nil)
 	Hub.BroadcastToGame(game.ID, "game_started", maskedGame)
 }
 
@@ -205,10 +378,24 @@ func BroadcastGameOver(gameOver GameOverData) {
 	gameOver.Game.Guesses = removeGuesses(gameOver.Game.Guesses)
 
 	maskedGameOver := GameOverData{
-		Game:    maskGameInfo(gameOver.Game),
+		Game:    maskGameInfo(gameOver.Game, nil),
 		Winner:  gameOver.Winner,
 		Word:    gameOver.Word,
 		Players: removePasswords(gameOver.Players),
 	}
 	Hub.BroadcastToGame(gameOver.Game.ID, "game_over", maskedGameOver)
 }
+
+// The game master already knows the word, so they get every guess unmasked
+func SendGuessToGameMaster(game models.Game, guess models.Guess) {
+	if game.GameMasterID == nil {
+		return
+	}
+
+	for _, player := range game.Players {
+		if player.ID == *game.GameMasterID {
+			Hub.SendToPlayer(game.ID, player.Username, "game_master_guess", guess)
+			return
+		}
+	}
+}
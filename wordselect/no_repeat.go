@@ -0,0 +1,63 @@
+package wordselect
+
+import (
+	"math/rand"
+	"multiplayer-wordle/models"
+
+	"gorm.io/gorm"
+)
+
+// NoRepeat avoids words already played in the game, and optionally by any of
+// its players in other games, until every word in the pool has been used
+type NoRepeat struct {
+	PerPlayer bool
+}
+
+func (s NoRepeat) Select(db *gorm.DB, req Request) (string, error) {
+	words, err := pool(req.WordLength)
+	if err != nil {
+		return "", err
+	}
+
+	history := db.Model(&models.Round{}).
+		Select("word, COUNT(*) AS uses").
+		Where("word_length = ?", req.WordLength).
+		Group("word")
+
+	if s.PerPlayer && len(req.PlayerIDs) > 0 {
+		played := db.Model(&models.RoundPlayer{}).Select("round_id").Where("player_id IN ?", req.PlayerIDs)
+		history = history.Where(db.Where("game_id = ?", req.GameID).Or("id IN (?)", played))
+	} else {
+		history = history.Where("game_id = ?", req.GameID)
+	}
+
+	var rows []struct {
+		Word string
+		Uses int
+	}
+	if err := history.Scan(&rows).Error; err != nil {
+		return "", err
+	}
+
+	uses := make(map[string]int, len(rows))
+	for _, row := range rows {
+		uses[row.Word] = row.Uses
+	}
+
+	// Pick among the least used words, once the pool is exhausted every word
+	// has been used once and the next cycle starts
+	var candidates []string
+	fewest := -1
+	for _, word := range words {
+		count := uses[word]
+		if fewest == -1 || count < fewest {
+			fewest = count
+			candidates = candidates[:0]
+		}
+		if count == fewest {
+			candidates = append(candidates, word)
+		}
+	}
+
+	return candidates[rand.Intn(len(candidates))], nil
+}
@@ -0,0 +1,17 @@
+package wordselect
+
+import (
+	"multiplayer-wordle/utils"
+
+	"gorm.io/gorm"
+)
+
+// Random picks uniformly from the answer pool every time
+type Random struct{}
+
+func (Random) Select(db *gorm.DB, req Request) (string, error) {
+	if _, err := pool(req.WordLength); err != nil {
+		return "", err
+	}
+	return utils.GetRandomWord(req.WordLength), nil
+}
@@ -0,0 +1,50 @@
+package wordselect
+
+import (
+	"multiplayer-wordle/models"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Scheduled plays the words the lobby admin queued for the game in order and
+// falls back to another selector once the schedule runs out
+type Scheduled struct {
+	Fallback Selector
+}
+
+// HasScheduled reports whether the admin queued a word for the next round of
+// the game, they already know it
+func HasScheduled(db *gorm.DB, gameID uint, wordLength int) (bool, error) {
+	var count int64
+	err := db.Model(&models.ScheduledWord{}).
+		Where("game_id = ? AND used_at IS NULL AND LENGTH(word) = ?", gameID, wordLength).
+		Count(&count).Error
+	return count > 0, err
+}
+
+func (s Scheduled) Select(db *gorm.DB, req Request) (string, error) {
+	if req.GameID != 0 {
+		var next models.ScheduledWord
+		err := db.Where("game_id = ? AND used_at IS NULL AND LENGTH(word) = ?", req.GameID, req.WordLength).
+			Order("position, id").
+			Limit(1).
+			Find(&next).Error
+		if err != nil {
+			return "", err
+		}
+
+		if next.ID != 0 {
+			usedAt := time.Now()
+			if err := db.Model(&next).Update("used_at", usedAt).Error; err != nil {
+				return "", err
+			}
+			return next.Word, nil
+		}
+	}
+
+	if s.Fallback == nil {
+		return Random{}.Select(db, req)
+	}
+	return s.Fallback.Select(db, req)
+}
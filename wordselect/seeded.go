@@ -0,0 +1,39 @@
+package wordselect
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/binary"
+	"errors"
+
+	"gorm.io/gorm"
+)
+
+// ErrNoSecret is returned by Seeded without a secret, anyone could work out
+// the word from the public word list
+var ErrNoSecret = errors.New("no secret to seed the word with")
+
+// Seeded derives the word from the request date and a secret, the same date
+// always gives the same word on every replica
+type Seeded struct {
+	Secret func() []byte
+}
+
+func (s Seeded) Select(db *gorm.DB, req Request) (string, error) {
+	words, err := pool(req.WordLength)
+	if err != nil {
+		return "", err
+	}
+
+	secret := s.Secret()
+	if len(secret) == 0 {
+		return "", ErrNoSecret
+	}
+
+	mac := hmac.New(sha256.New, secret)
+	mac.Write([]byte(req.Date))
+	sum := mac.Sum(nil)
+
+	index := binary.BigEndian.Uint64(sum[:8]) % uint64(len(words))
+	return words[index], nil
+}
@@ -0,0 +1,42 @@
+package wordselect
+
+import (
+	"errors"
+	"multiplayer-wordle/constants"
+
+	"gorm.io/gorm"
+)
+
+var ErrNoWords = errors.New("no words available for this word length")
+
+// Request describes what a word is being picked for
+type Request struct {
+	GameID     uint   // 0 outside of multiplayer games
+	PlayerIDs  []uint // Players that should not see a repeat
+	WordLength int
+	Date       string // Set for the daily challenge
+}
+
+// Selector picks the secret word for a round
+type Selector interface {
+	Select(db *gorm.DB, req Request) (string, error)
+}
+
+// SelectorFunc lets a plain function be used as a Selector, mostly for tests
+type SelectorFunc func(db *gorm.DB, req Request) (string, error)
+
+func (f SelectorFunc) Select(db *gorm.DB, req Request) (string, error) {
+	return f(db, req)
+}
+
+// Default is used when a multiplayer game starts
+var Default Selector = Scheduled{Fallback: NoRepeat{}}
+
+// Returns the answer pool for a word length
+func pool(wordLength int) ([]string, error) {
+	words, ok := constants.WordLists[wordLength]
+	if !ok || len(words) == 0 {
+		return nil, ErrNoWords
+	}
+	return words, nil
+}